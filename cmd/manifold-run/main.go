@@ -0,0 +1,83 @@
+// Command manifold-run runs a process with the credentials of a Manifold
+// project injected into its environment.
+//
+//	manifold-run [flags] -- command [args...]
+//
+// The project is read from a manifold.yml or manifold.json manifest in the
+// current directory, unless a project label is given with -project. The API
+// token is read from MANIFOLD_API_TOKEN.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+func main() {
+	code, err := run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "manifold-run: %s\n", err)
+		if code <= 0 {
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
+func run() (int, error) {
+	fs := flag.NewFlagSet("manifold-run", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: manifold-run [flags] -- command [args...]")
+		fs.PrintDefaults()
+	}
+
+	manifest := fs.String("manifest", ".", "path to the manifest file, or the directory containing it")
+	project := fs.String("project", "", "label of the project to load credentials from, ignores the manifest")
+	team := fs.String("team", os.Getenv("MANIFOLD_TEAM"), "label of the team owning the project")
+	envWins := fs.Bool("env-wins", false, "keep existing environment variables instead of overriding them")
+	restart := fs.Duration("restart-interval", 0, "poll for rotated credentials and restart the command when they change")
+	stopTimeout := fs.Duration("stop-timeout", 10*time.Second, "time the command is given to exit before it is killed")
+
+	fs.Parse(os.Args[1:])
+
+	args := fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		return 2, nil
+	}
+
+	p := &primitives.Project{Name: *project}
+	if *project == "" {
+		var err error
+		if p, err = primitives.LoadManifest(*manifest); err != nil {
+			return -1, err
+		}
+	}
+
+	if p.Team != "" && *team == "" {
+		team = &p.Team
+	}
+
+	c, err := integrations.NewClient(manifold.New(), team)
+	if err != nil {
+		return -1, err
+	}
+
+	opts := &integrations.RunOpts{
+		RestartInterval: *restart,
+		StopTimeout:     *stopTimeout,
+	}
+	if *envWins {
+		opts.Precedence = integrations.PrecedenceEnvironment
+	}
+
+	return c.Run(context.Background(), p, args[0], args[1:], opts)
+}
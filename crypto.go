@@ -31,3 +31,9 @@ func sign(privkey ed25519.PrivateKey, token string) *base64.Value {
 	b := ed25519.Sign(privkey, []byte(token))
 	return base64.New(b)
 }
+
+// DeriveKey stretches a password and salt into a key of the given size, using
+// the same scrypt parameters used to derive login keys.
+func DeriveKey(password string, salt []byte, size int) ([]byte, error) {
+	return scrypt.Key([]byte(password), salt, n, r, p, size)
+}
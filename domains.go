@@ -0,0 +1,131 @@
+package manifold
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/manifoldco/go-manifold/errors"
+)
+
+var (
+	// ErrNoDomainResolver is returned when resolving an Identifier of a
+	//  domain which has no registered resolver.
+	ErrNoDomainResolver = NewError(errors.NotImplementedError,
+		"No resolver is registered for the Identifier's Domain")
+	// ErrUnknownClass is returned when an Identifier uses a Class that is not
+	//  registered for its Domain.
+	ErrUnknownClass = NewError(errors.BadRequestError,
+		"Invalid Identifier, the Class is not registered for the Domain")
+)
+
+// DomainResolver dereferences the Identifiers of a Domain into the object
+// they identify.
+type DomainResolver interface {
+	Resolve(ctx context.Context, id FlexID) (interface{}, error)
+}
+
+// DomainResolverFunc is a function implementing the DomainResolver interface.
+type DomainResolverFunc func(ctx context.Context, id FlexID) (interface{}, error)
+
+// Resolve calls the function.
+func (fn DomainResolverFunc) Resolve(ctx context.Context, id FlexID) (interface{}, error) {
+	return fn(ctx, id)
+}
+
+// DomainSpec holds the rules applied to the FlexIDs of a Domain.
+type DomainSpec struct {
+	Domain Domain
+
+	// Classes maps the valid classes of the domain to an optional validator
+	// for their IDs. When Classes is nil, every class is valid.
+	Classes map[Class]func(ExternalID) error
+
+	// Canonicalize returns the canonical form of an ID, like lowercasing
+	// case-insensitive IDs. Canonical forms are used to compare FlexIDs.
+	Canonicalize func(FlexID) FlexID
+
+	// Resolver optionally dereferences the IDs of the domain.
+	Resolver DomainResolver
+}
+
+var domains = struct {
+	sync.RWMutex
+	specs map[Domain]*DomainSpec
+}{specs: map[Domain]*DomainSpec{}}
+
+// RegisterDomain registers the rules of a domain. FlexID.Validate and
+// FlexID.Equals apply these rules to the IDs of the domain, and
+// ResolveIdentifier uses its resolver.
+//
+// RegisterDomain is meant to be called from init functions, and panics if the
+// domain is invalid or already registered.
+func RegisterDomain(spec *DomainSpec) {
+	if err := spec.Domain.Validate(nil); err != nil {
+		panic(fmt.Sprintf("invalid domain %q", spec.Domain))
+	}
+
+	domains.Lock()
+	defer domains.Unlock()
+
+	if _, ok := domains.specs[spec.Domain]; ok {
+		panic(fmt.Sprintf("domain %q is already registered", spec.Domain))
+	}
+
+	domains.specs[spec.Domain] = spec
+}
+
+// LookupDomain returns the rules registered for the domain.
+func LookupDomain(d Domain) (*DomainSpec, bool) {
+	domains.RLock()
+	defer domains.RUnlock()
+
+	spec, ok := domains.specs[d]
+	return spec, ok
+}
+
+// ResolveIdentifier dereferences the Identifier using the resolver registered
+// for its domain.
+func ResolveIdentifier(ctx context.Context, id Identifier) (interface{}, error) {
+	spec, ok := LookupDomain(id.Domain())
+	if !ok || spec.Resolver == nil {
+		return nil, ErrNoDomainResolver
+	}
+
+	return spec.Resolver.Resolve(ctx, id.AsFlexID().Canonical())
+}
+
+// Canonical returns the canonical form of the FlexID, according to the rules
+// registered for its domain. It is returned as is when there are no rules.
+func (id FlexID) Canonical() FlexID {
+	spec, ok := LookupDomain(id.Domain())
+	if !ok || spec.Canonicalize == nil {
+		return id
+	}
+
+	return spec.Canonicalize(id)
+}
+
+// validateDomainRules applies the rules registered for the domain of the
+// FlexID.
+func (id FlexID) validateDomainRules() error {
+	spec, ok := LookupDomain(id.Domain())
+	if !ok || spec.Classes == nil {
+		return nil
+	}
+
+	validate, ok := spec.Classes[id.Class()]
+	if !ok {
+		return ErrUnknownClass
+	}
+
+	if validate == nil {
+		return nil
+	}
+
+	if err := validate(id.ID()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package manifold
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var errInvalidRepo = errors.New("invalid repository ID")
+
+func init() {
+	numeric := regexp.MustCompile(`^[0-9]+$`)
+
+	RegisterDomain(&DomainSpec{
+		Domain: "github.test",
+		Classes: map[Class]func(ExternalID) error{
+			"repository": func(id ExternalID) error {
+				if !numeric.MatchString(id.String()) {
+					return errInvalidRepo
+				}
+				return nil
+			},
+			"user": nil,
+		},
+		Canonicalize: func(id FlexID) FlexID {
+			if id.Class() == "user" {
+				id[2] = strings.ToLower(id[2])
+			}
+			return id
+		},
+		Resolver: DomainResolverFunc(func(_ context.Context, id FlexID) (interface{}, error) {
+			return "resolved " + id.String(), nil
+		}),
+	})
+}
+
+func TestFlexID_DomainRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      FlexID
+		wantErr error
+	}{
+		{name: "valid class", id: FlexID{"github.test", "repository", "1234"}},
+		{name: "class without validator", id: FlexID{"github.test", "user", "Octocat"}},
+		{name: "invalid ID", id: FlexID{"github.test", "repository", "abc"}, wantErr: errInvalidRepo},
+		{name: "unknown class", id: FlexID{"github.test", "gist", "1234"}, wantErr: ErrUnknownClass},
+		{name: "unregistered domain", id: FlexID{"web.com", "gist", "1234"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.id.Validate(nil); err != tt.wantErr {
+				t.Errorf("FlexID.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlexID_CanonicalEquals(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b FlexID
+		want bool
+	}{
+		{
+			name: "canonicalized class",
+			a:    FlexID{"github.test", "user", "Octocat"},
+			b:    FlexID{"github.test", "user", "octocat"},
+			want: true,
+		},
+		{
+			name: "case sensitive class",
+			a:    FlexID{"github.test", "repository", "Abc"},
+			b:    FlexID{"github.test", "repository", "abc"},
+			want: false,
+		},
+		{
+			name: "unregistered domain",
+			a:    FlexID{"web.com", "user", "Octocat"},
+			b:    FlexID{"web.com", "user", "octocat"},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equals(tt.b); got != tt.want {
+				t.Errorf("FlexID.Equals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIdentifier(t *testing.T) {
+	ctx := context.Background()
+
+	got, err := ResolveIdentifier(ctx, FlexID{"github.test", "user", "Octocat"})
+	if err != nil {
+		t.Fatalf("ResolveIdentifier() error = %v", err)
+	}
+	if want := "resolved github.test/user/octocat"; got != want {
+		t.Errorf("ResolveIdentifier() = %v, want %v", got, want)
+	}
+
+	if _, err := ResolveIdentifier(ctx, validID); err != ErrNoDomainResolver {
+		t.Errorf("ResolveIdentifier() error = %v, want %v", err, ErrNoDomainResolver)
+	}
+}
+
+func TestRegisterDomain(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *DomainSpec
+	}{
+		{name: "duplicate domain", spec: &DomainSpec{Domain: "github.test"}},
+		{name: "invalid domain", spec: &DomainSpec{Domain: "*@#"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterDomain() expected a panic")
+				}
+			}()
+			RegisterDomain(tt.spec)
+		})
+	}
+}
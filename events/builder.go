@@ -0,0 +1,193 @@
+package events
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/errors"
+)
+
+var errMissingData = manifold.NewError(errors.BadRequestError, "Expected event data")
+
+// Builder builds events, setting the optional fields of their body. The zero
+// value builds events from the system, created when they are built.
+type Builder struct {
+	source    SourceType
+	ipAddress string
+	refID     manifold.ID
+	createdAt *time.Time
+}
+
+// NewBuilder returns a Builder for events with optional fields.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Source sets where the request resulting in the event came from.
+func (b *Builder) Source(s SourceType) *Builder {
+	b.source = s
+	return b
+}
+
+// IPAddress sets the IP address of the request resulting in the event.
+func (b *Builder) IPAddress(ip string) *Builder {
+	b.ipAddress = ip
+	return b
+}
+
+// RefID sets the ID of the object the event refers to.
+func (b *Builder) RefID(id manifold.ID) *Builder {
+	b.refID = id
+	return b
+}
+
+// CreatedAt sets the time of the event, instead of the time it is built.
+func (b *Builder) CreatedAt(t time.Time) *Builder {
+	b.createdAt = &t
+	return b
+}
+
+// build returns a new event with the body, after filling and validating it.
+func (b *Builder) build(t Type, body Body, actor *Actor, scope *Scope, hasData bool) (*Event, error) {
+	if !hasData {
+		return nil, errMissingData
+	}
+
+	evt, err := New()
+	if err != nil {
+		return nil, err
+	}
+
+	body.SetType(string(t))
+	body.SetActor(actor)
+	body.SetScope(scope)
+	body.SetRefID(b.refID)
+	body.SetIPAddress(b.ipAddress)
+
+	var createdAt *strfmt.DateTime
+	if b.createdAt != nil {
+		dt := strfmt.DateTime(b.createdAt.UTC())
+		createdAt = &dt
+	}
+	body.SetCreatedAt(createdAt)
+
+	var source *string
+	if b.source != "" {
+		s := string(b.source)
+		source = &s
+	}
+	body.SetSource(source)
+
+	if err := SourceType(*body.Source()).Validate(nil); err != nil {
+		return nil, err
+	}
+
+	evt.Body = body
+	if err := evt.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	return evt, nil
+}
+
+// OperationProvisioned returns a new OperationProvisioned event with the builder's optional fields.
+func (b *Builder) OperationProvisioned(actor *Actor, scope *Scope, data *OperationProvisionedData) (*Event, error) {
+	return b.build(TypeOperationProvisioned, &OperationProvisioned{Data: data}, actor, scope, data != nil)
+}
+
+// NewOperationProvisioned returns a new OperationProvisioned event from the system.
+func NewOperationProvisioned(actor *Actor, scope *Scope, data *OperationProvisionedData) (*Event, error) {
+	return NewBuilder().OperationProvisioned(actor, scope, data)
+}
+
+// OperationDeprovisioned returns a new OperationDeprovisioned event with the builder's optional fields.
+func (b *Builder) OperationDeprovisioned(actor *Actor, scope *Scope, data *OperationDeprovisionedData) (*Event, error) {
+	return b.build(TypeOperationDeprovisioned, &OperationDeprovisioned{Data: data}, actor, scope, data != nil)
+}
+
+// NewOperationDeprovisioned returns a new OperationDeprovisioned event from the system.
+func NewOperationDeprovisioned(actor *Actor, scope *Scope, data *OperationDeprovisionedData) (*Event, error) {
+	return NewBuilder().OperationDeprovisioned(actor, scope, data)
+}
+
+// OperationResized returns a new OperationResized event with the builder's optional fields.
+func (b *Builder) OperationResized(actor *Actor, scope *Scope, data *OperationResizedData) (*Event, error) {
+	return b.build(TypeOperationResized, &OperationResized{Data: data}, actor, scope, data != nil)
+}
+
+// NewOperationResized returns a new OperationResized event from the system.
+func NewOperationResized(actor *Actor, scope *Scope, data *OperationResizedData) (*Event, error) {
+	return NewBuilder().OperationResized(actor, scope, data)
+}
+
+// ResourceProjectChanged returns a new ResourceProjectChanged event with the builder's optional fields.
+func (b *Builder) ResourceProjectChanged(actor *Actor, scope *Scope, data *ResourceProjectChangedData) (*Event, error) {
+	return b.build(TypeResourceProjectChanged, &ResourceProjectChanged{Data: data}, actor, scope, data != nil)
+}
+
+// NewResourceProjectChanged returns a new ResourceProjectChanged event from the system.
+func NewResourceProjectChanged(actor *Actor, scope *Scope, data *ResourceProjectChangedData) (*Event, error) {
+	return NewBuilder().ResourceProjectChanged(actor, scope, data)
+}
+
+// ResourceOwnerChanged returns a new ResourceOwnerChanged event with the builder's optional fields.
+func (b *Builder) ResourceOwnerChanged(actor *Actor, scope *Scope, data *ResourceOwnerChangedData) (*Event, error) {
+	return b.build(TypeResourceOwnerChanged, &ResourceOwnerChanged{Data: data}, actor, scope, data != nil)
+}
+
+// NewResourceOwnerChanged returns a new ResourceOwnerChanged event from the system.
+func NewResourceOwnerChanged(actor *Actor, scope *Scope, data *ResourceOwnerChangedData) (*Event, error) {
+	return NewBuilder().ResourceOwnerChanged(actor, scope, data)
+}
+
+// OperationFailed returns a new OperationFailed event with the builder's optional fields.
+func (b *Builder) OperationFailed(actor *Actor, scope *Scope, data *OperationFailedData) (*Event, error) {
+	return b.build(TypeOperationFailed, &OperationFailed{Data: data}, actor, scope, data != nil)
+}
+
+// NewOperationFailed returns a new OperationFailed event from the system.
+func NewOperationFailed(actor *Actor, scope *Scope, data *OperationFailedData) (*Event, error) {
+	return NewBuilder().OperationFailed(actor, scope, data)
+}
+
+// ResourceMeasuresAdded returns a new ResourceMeasuresAdded event with the builder's optional fields.
+func (b *Builder) ResourceMeasuresAdded(actor *Actor, scope *Scope, data *ResourceMeasuresAddedData) (*Event, error) {
+	return b.build(TypeResourceMeasuresAdded, &ResourceMeasuresAdded{Data: data}, actor, scope, data != nil)
+}
+
+// NewResourceMeasuresAdded returns a new ResourceMeasuresAdded event from the system.
+func NewResourceMeasuresAdded(actor *Actor, scope *Scope, data *ResourceMeasuresAddedData) (*Event, error) {
+	return NewBuilder().ResourceMeasuresAdded(actor, scope, data)
+}
+
+// ResourceMeasuresFailed returns a new ResourceMeasuresFailed event with the builder's optional fields.
+func (b *Builder) ResourceMeasuresFailed(actor *Actor, scope *Scope, data *ResourceMeasuresFailedData) (*Event, error) {
+	return b.build(TypeResourceMeasuresFailed, &ResourceMeasuresFailed{Data: data}, actor, scope, data != nil)
+}
+
+// NewResourceMeasuresFailed returns a new ResourceMeasuresFailed event from the system.
+func NewResourceMeasuresFailed(actor *Actor, scope *Scope, data *ResourceMeasuresFailedData) (*Event, error) {
+	return NewBuilder().ResourceMeasuresFailed(actor, scope, data)
+}
+
+// AccountStatusUpdated returns a new AccountStatusUpdated event with the builder's optional fields.
+func (b *Builder) AccountStatusUpdated(actor *Actor, scope *Scope, data *AccountStatusUpdatedData) (*Event, error) {
+	return b.build(TypeAccountStatusUpdated, &AccountStatusUpdated{Data: data}, actor, scope, data != nil)
+}
+
+// NewAccountStatusUpdated returns a new AccountStatusUpdated event from the system.
+func NewAccountStatusUpdated(actor *Actor, scope *Scope, data *AccountStatusUpdatedData) (*Event, error) {
+	return NewBuilder().AccountStatusUpdated(actor, scope, data)
+}
+
+// AccountUpdated returns a new AccountUpdated event with the builder's optional fields.
+func (b *Builder) AccountUpdated(actor *Actor, scope *Scope, data *AccountUpdatedData) (*Event, error) {
+	return b.build(TypeAccountUpdated, &AccountUpdated{Data: data}, actor, scope, data != nil)
+}
+
+// NewAccountUpdated returns a new AccountUpdated event from the system.
+func NewAccountUpdated(actor *Actor, scope *Scope, data *AccountUpdatedData) (*Event, error) {
+	return NewBuilder().AccountUpdated(actor, scope, data)
+}
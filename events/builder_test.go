@@ -0,0 +1,99 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func TestNewOperationProvisioned(t *testing.T) {
+	actor := &Actor{ID: manifold.MustNewID(idtype.User), Name: "luiz"}
+	scope := &Scope{ID: manifold.MustNewID(idtype.Team), Name: "manifold"}
+
+	evt, err := NewOperationProvisioned(actor, scope, &OperationProvisionedData{Source: "catalog"})
+	if err != nil {
+		t.Fatalf("NewOperationProvisioned() error = %v", err)
+	}
+
+	if evt.ID.IsEmpty() || evt.ID.Type() != idtype.ActivityEvent {
+		t.Errorf("expected an event ID, got %v", evt.ID)
+	}
+
+	body, ok := evt.Body.(*OperationProvisioned)
+	if !ok {
+		t.Fatalf("expected an OperationProvisioned body, got %T", evt.Body)
+	}
+	if body.Type() != TypeOperationProvisioned {
+		t.Errorf("expected type %q, got %q", TypeOperationProvisioned, body.Type())
+	}
+	if body.Actor() != actor || body.Scope() != scope {
+		t.Error("expected the actor and scope to be set")
+	}
+	if *body.Source() != string(SourceSystem) {
+		t.Errorf("expected the system source, got %q", *body.Source())
+	}
+	if time.Since(body.StructCreatedAt) > time.Minute {
+		t.Errorf("expected the event to be created now, got %v", body.StructCreatedAt)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	refID := manifold.MustNewID(idtype.Resource)
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		build   func(b *Builder) (*Event, error)
+		wantErr bool
+	}{
+		{
+			name: "account updated",
+			build: func(b *Builder) (*Event, error) {
+				return b.AccountUpdated(nil, nil, &AccountUpdatedData{Name: "luiz"})
+			},
+		},
+		{
+			name: "resource measures added",
+			build: func(b *Builder) (*Event, error) {
+				return b.ResourceMeasuresAdded(nil, nil, &ResourceMeasuresAddedData{})
+			},
+		},
+		{
+			name: "missing data",
+			build: func(b *Builder) (*Event, error) {
+				return b.OperationFailed(nil, nil, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid source",
+			build: func(b *Builder) (*Event, error) {
+				return b.Source("fax").AccountUpdated(nil, nil, &AccountUpdatedData{})
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuilder().Source(SourceCLI).IPAddress("127.0.0.1").RefID(refID).CreatedAt(createdAt)
+
+			evt, err := tt.build(b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Builder error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			body := evt.Body
+			if *body.Source() != string(SourceCLI) || body.IPAddress() != "127.0.0.1" || body.RefID() != refID {
+				t.Errorf("expected the optional fields to be set, got %#v", body)
+			}
+			if !time.Time(*body.CreatedAt()).Equal(createdAt) {
+				t.Errorf("expected created at %v, got %v", createdAt, body.CreatedAt())
+			}
+		})
+	}
+}
@@ -117,6 +117,10 @@ func (e *Event) Validate(v strfmt.Registry) error {
 		return manifold.NewError(errors.BadRequestError, "Expected version to be 1")
 	}
 
+	if err := validateBodyType(e.Body); err != nil {
+		return err
+	}
+
 	return e.Body.Validate(v)
 }
 
@@ -160,33 +164,40 @@ func (e *Event) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	var body Body
-	switch v.Type() {
-	case TypeOperationProvisioned:
-		body = &OperationProvisioned{}
-	case TypeOperationDeprovisioned:
-		body = &OperationDeprovisioned{}
-	case TypeOperationResized:
-		body = &OperationResized{}
-	case TypeResourceProjectChanged:
-		body = &ResourceProjectChanged{}
-	case TypeResourceOwnerChanged:
-		body = &ResourceOwnerChanged{}
-	case TypeOperationFailed:
-		body = &OperationFailed{}
-	case TypeResourceMeasuresAdded:
-		body = &ResourceMeasuresAdded{}
-	case TypeResourceMeasuresFailed:
-		body = &ResourceMeasuresFailed{}
-	case TypeAccountStatusUpdated:
-		body = &AccountStatusUpdated{}
-	case TypeAccountUpdated:
-		body = &AccountUpdated{}
-	default:
-		return fmt.Errorf("Unrecognized Operation Type: %s", v.Type())
+	body, ok := newBody(v.Type())
+	if !ok {
+		return &UnknownTypeError{Type: v.Type()}
+	}
+
+	return e.fill(o, body)
+}
+
+// UnmarshalLenient decodes an event like json.Unmarshal, but decodes the body
+// of unknown event types into a RawBody instead of failing.
+func UnmarshalLenient(b []byte, e *Event) error {
+	o := outEvent{}
+	err := json.Unmarshal(b, &o)
+	if err != nil {
+		return err
+	}
+
+	v := BaseBody{}
+	err = json.Unmarshal(o.Body, &v)
+	if err != nil {
+		return err
+	}
+
+	body, ok := newBody(v.Type())
+	if !ok {
+		body = &RawBody{}
 	}
 
-	err = json.Unmarshal(o.Body, body)
+	return e.fill(o, body)
+}
+
+// fill decodes the body of the event into the given body.
+func (e *Event) fill(o outEvent, body Body) error {
+	err := json.Unmarshal(o.Body, body)
 	if err != nil {
 		return err
 	}
@@ -199,6 +210,15 @@ func (e *Event) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// UnknownTypeError is returned when decoding an event of an unknown type.
+type UnknownTypeError struct {
+	Type Type
+}
+
+func (e *UnknownTypeError) Error() string {
+	return fmt.Sprintf("Unrecognized Operation Type: %s", e.Type)
+}
+
 // Body represents methods all Events must implement.
 type Body interface {
 	Validate(interface{}) error
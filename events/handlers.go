@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"fmt"
+)
+
+// typedHandlers names the Mux method registering a typed handler for every
+// built-in event type.
+var typedHandlers = map[Type]string{
+	TypeOperationProvisioned:   "HandleOperationProvisioned",
+	TypeOperationDeprovisioned: "HandleOperationDeprovisioned",
+	TypeOperationResized:       "HandleOperationResized",
+	TypeResourceProjectChanged: "HandleResourceProjectChanged",
+	TypeResourceOwnerChanged:   "HandleResourceOwnerChanged",
+	TypeOperationFailed:        "HandleOperationFailed",
+	TypeResourceMeasuresAdded:  "HandleResourceMeasuresAdded",
+	TypeResourceMeasuresFailed: "HandleResourceMeasuresFailed",
+	TypeAccountStatusUpdated:   "HandleAccountStatusUpdated",
+	TypeAccountUpdated:         "HandleAccountUpdated",
+}
+
+// unexpectedBody is returned when an event doesn't hold the body registered
+// for its type, like an event built by hand.
+func unexpectedBody(e *Event, want Body) error {
+	return fmt.Errorf("events: expected a %T body for %q, got %T", want, e.Body.Type(), e.Body)
+}
+
+// HandleOperationProvisioned registers the handler for OperationProvisioned events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleOperationProvisioned(fn func(context.Context, *Event, *OperationProvisioned) error) {
+	m.handle(TypeOperationProvisioned, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*OperationProvisioned)
+		if !ok {
+			return unexpectedBody(e, (*OperationProvisioned)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleOperationDeprovisioned registers the handler for OperationDeprovisioned events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleOperationDeprovisioned(fn func(context.Context, *Event, *OperationDeprovisioned) error) {
+	m.handle(TypeOperationDeprovisioned, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*OperationDeprovisioned)
+		if !ok {
+			return unexpectedBody(e, (*OperationDeprovisioned)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleOperationResized registers the handler for OperationResized events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleOperationResized(fn func(context.Context, *Event, *OperationResized) error) {
+	m.handle(TypeOperationResized, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*OperationResized)
+		if !ok {
+			return unexpectedBody(e, (*OperationResized)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleResourceProjectChanged registers the handler for ResourceProjectChanged events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleResourceProjectChanged(fn func(context.Context, *Event, *ResourceProjectChanged) error) {
+	m.handle(TypeResourceProjectChanged, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*ResourceProjectChanged)
+		if !ok {
+			return unexpectedBody(e, (*ResourceProjectChanged)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleResourceOwnerChanged registers the handler for ResourceOwnerChanged events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleResourceOwnerChanged(fn func(context.Context, *Event, *ResourceOwnerChanged) error) {
+	m.handle(TypeResourceOwnerChanged, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*ResourceOwnerChanged)
+		if !ok {
+			return unexpectedBody(e, (*ResourceOwnerChanged)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleOperationFailed registers the handler for OperationFailed events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleOperationFailed(fn func(context.Context, *Event, *OperationFailed) error) {
+	m.handle(TypeOperationFailed, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*OperationFailed)
+		if !ok {
+			return unexpectedBody(e, (*OperationFailed)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleResourceMeasuresAdded registers the handler for ResourceMeasuresAdded events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleResourceMeasuresAdded(fn func(context.Context, *Event, *ResourceMeasuresAdded) error) {
+	m.handle(TypeResourceMeasuresAdded, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*ResourceMeasuresAdded)
+		if !ok {
+			return unexpectedBody(e, (*ResourceMeasuresAdded)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleResourceMeasuresFailed registers the handler for ResourceMeasuresFailed events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleResourceMeasuresFailed(fn func(context.Context, *Event, *ResourceMeasuresFailed) error) {
+	m.handle(TypeResourceMeasuresFailed, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*ResourceMeasuresFailed)
+		if !ok {
+			return unexpectedBody(e, (*ResourceMeasuresFailed)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleAccountStatusUpdated registers the handler for AccountStatusUpdated events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleAccountStatusUpdated(fn func(context.Context, *Event, *AccountStatusUpdated) error) {
+	m.handle(TypeAccountStatusUpdated, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*AccountStatusUpdated)
+		if !ok {
+			return unexpectedBody(e, (*AccountStatusUpdated)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
+
+// HandleAccountUpdated registers the handler for AccountUpdated events. It panics if a
+// handler is already registered for the type.
+func (m *Mux) HandleAccountUpdated(fn func(context.Context, *Event, *AccountUpdated) error) {
+	m.handle(TypeAccountUpdated, HandlerFunc(func(ctx context.Context, e *Event) error {
+		b, ok := e.Body.(*AccountUpdated)
+		if !ok {
+			return unexpectedBody(e, (*AccountUpdated)(nil))
+		}
+		return fn(ctx, e, b)
+	}))
+}
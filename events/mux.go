@@ -0,0 +1,332 @@
+package events
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+)
+
+// ErrNoHandler is returned when dispatching an event for which no handler or
+// fallback is registered.
+var ErrNoHandler = errors.New("no handler registered for the event type")
+
+// Handler handles events dispatched by a Mux.
+type Handler interface {
+	HandleEvent(ctx context.Context, e *Event) error
+}
+
+// HandlerFunc is a function implementing the Handler interface.
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+// HandleEvent calls the function.
+func (fn HandlerFunc) HandleEvent(ctx context.Context, e *Event) error {
+	return fn(ctx, e)
+}
+
+// Middleware wraps the handling of every event dispatched by a Mux.
+type Middleware func(Handler) Handler
+
+// Mux dispatches events to the handler registered for their type.
+type Mux struct {
+	mu         sync.RWMutex
+	handlers   map[Type]Handler
+	fallback   Handler
+	middleware []Middleware
+}
+
+// NewMux returns an empty Mux.
+func NewMux() *Mux {
+	return &Mux{handlers: map[Type]Handler{}}
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	eventType   = reflect.TypeOf((*Event)(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Handle registers the handler for events of the given type. The handler is
+// either a Handler or a func(context.Context, *Event) error.
+//
+// Built-in event types have typed methods instead, like
+// HandleOperationProvisioned, so the body of their handlers is checked at
+// compile time. For bodies registered with RegisterBody, the handler can also
+// be a function receiving the concrete body, like:
+//
+//	mux.Handle(TypeTeamCreated,
+//		func(ctx context.Context, e *events.Event, b *TeamCreated) error {
+//			...
+//		})
+//
+// Handle panics if the handler doesn't match the type, or if a handler is
+// already registered for it.
+func (m *Mux) Handle(t Type, handler interface{}) {
+	h, err := adaptHandler(t, handler)
+	if err != nil {
+		panic(fmt.Sprintf("events: invalid handler for %q: %s", t, err))
+	}
+
+	m.handle(t, h)
+}
+
+func (m *Mux) handle(t Type, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.handlers[t]; ok {
+		panic(fmt.Sprintf("events: a handler is already registered for %q", t))
+	}
+
+	m.handlers[t] = h
+}
+
+// Fallback sets the handler of events without a registered handler,
+// including events of unknown types. Without a fallback, dispatching those
+// events returns ErrNoHandler.
+func (m *Mux) Fallback(h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fallback = h
+}
+
+// Use adds middleware wrapping the handling of every event, the first one
+// being the outermost.
+func (m *Mux) Use(mw ...Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.middleware = append(m.middleware, mw...)
+}
+
+// HandleEvent implements the Handler interface, see Dispatch.
+func (m *Mux) HandleEvent(ctx context.Context, e *Event) error {
+	return m.Dispatch(ctx, e)
+}
+
+// Dispatch calls the handler registered for the type of the event, through
+// the middleware.
+func (m *Mux) Dispatch(ctx context.Context, e *Event) error {
+	m.mu.RLock()
+	var h Handler = HandlerFunc(m.route)
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		h = m.middleware[i](h)
+	}
+	m.mu.RUnlock()
+
+	return h.HandleEvent(ctx, e)
+}
+
+// DispatchJSON decodes the event and dispatches it. Events of unknown types
+// are decoded leniently, and given to the fallback with a *RawBody.
+func (m *Mux) DispatchJSON(ctx context.Context, b []byte) error {
+	e := &Event{}
+	if err := UnmarshalLenient(b, e); err != nil {
+		return err
+	}
+
+	return m.Dispatch(ctx, e)
+}
+
+func (m *Mux) route(ctx context.Context, e *Event) error {
+	if e.Body == nil {
+		return errors.New("events: cannot dispatch an event without a body")
+	}
+
+	m.mu.RLock()
+	h, ok := m.handlers[e.Body.Type()]
+	if !ok {
+		h = m.fallback
+	}
+	m.mu.RUnlock()
+
+	if h == nil {
+		return fmt.Errorf("%w: %q", ErrNoHandler, e.Body.Type())
+	}
+
+	return h.HandleEvent(ctx, e)
+}
+
+// adaptHandler turns the supported handler forms into a Handler, checking
+// typed handlers against the body of the event type.
+func adaptHandler(t Type, handler interface{}) (Handler, error) {
+	switch h := handler.(type) {
+	case Handler:
+		return h, nil
+	case func(context.Context, *Event) error:
+		return HandlerFunc(h), nil
+	}
+
+	if method, ok := typedHandlers[t]; ok {
+		return nil, fmt.Errorf("expected a Handler, use Mux.%s for a typed handler", method)
+	}
+
+	fn := reflect.ValueOf(handler)
+	ft := fn.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 3 || ft.NumOut() != 1 ||
+		ft.In(0) != contextType || ft.In(1) != eventType || ft.Out(0) != errorType {
+		return nil, fmt.Errorf("expected a func(context.Context, *Event, *Body) error, got %s", ft)
+	}
+
+	body, ok := newBody(t)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", t)
+	}
+
+	bodyType := reflect.TypeOf(body)
+	if ft.In(2) != bodyType {
+		return nil, fmt.Errorf("expected a %s body, got %s", bodyType, ft.In(2))
+	}
+
+	return HandlerFunc(func(ctx context.Context, e *Event) error {
+		if reflect.TypeOf(e.Body) != bodyType {
+			return fmt.Errorf("events: expected a %s body for %q, got %T", bodyType, t, e.Body)
+		}
+
+		out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(e), reflect.ValueOf(e.Body)})
+		err, _ := out[0].Interface().(error)
+		return err
+	}), nil
+}
+
+// Logging returns middleware logging the type, ID, duration and error of
+// every event handled, with a Printf-like function such as log.Printf.
+func Logging(logf func(format string, args ...interface{})) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, e *Event) error {
+			start := time.Now()
+			err := next.HandleEvent(ctx, e)
+
+			// Middleware runs before routing, so the body may still be missing.
+			typ := Type("<no body>")
+			if e.Body != nil {
+				typ = e.Body.Type()
+			}
+
+			if err != nil {
+				logf("event %s %s failed after %s: %s", typ, e.ID, time.Since(start), err)
+			} else {
+				logf("event %s %s handled in %s", typ, e.ID, time.Since(start))
+			}
+
+			return err
+		})
+	}
+}
+
+// PanicError is returned by the Recovery middleware when a handler panics.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("events: handler panicked: %v", e.Value)
+}
+
+// Recovery returns middleware turning panics in handlers into PanicErrors.
+func Recovery() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, e *Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = &PanicError{Value: r, Stack: debug.Stack()}
+				}
+			}()
+
+			return next.HandleEvent(ctx, e)
+		})
+	}
+}
+
+// Dedup returns middleware skipping events whose ID was already handled
+// successfully, remembering the last size IDs. Events which failed can be
+// dispatched again. An event dispatched while another with the same ID is
+// being handled waits for it, and is only handled if the other one failed.
+func Dedup(size int) Middleware {
+	d := &dedup{
+		size:     size,
+		order:    list.New(),
+		seen:     map[manifold.ID]*list.Element{},
+		inflight: map[manifold.ID]chan struct{}{},
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, e *Event) error {
+			ok, err := d.claim(ctx, e.ID)
+			if !ok || err != nil {
+				return err
+			}
+
+			// Released in a defer, so a panicking handler doesn't block
+			// the events with the same ID.
+			handled := false
+			defer func() { d.release(e.ID, handled) }()
+
+			err = next.HandleEvent(ctx, e)
+			handled = err == nil
+			return err
+		})
+	}
+}
+
+type dedup struct {
+	mu       sync.Mutex
+	size     int
+	order    *list.List
+	seen     map[manifold.ID]*list.Element
+	inflight map[manifold.ID]chan struct{}
+}
+
+// claim marks the ID as in flight, returning false if it was already handled.
+// It waits for an event with the same ID that is in flight to be released.
+func (d *dedup) claim(ctx context.Context, id manifold.ID) (bool, error) {
+	for {
+		d.mu.Lock()
+		if _, ok := d.seen[id]; ok {
+			d.mu.Unlock()
+			return false, nil
+		}
+
+		done, ok := d.inflight[id]
+		if !ok {
+			d.inflight[id] = make(chan struct{})
+			d.mu.Unlock()
+			return true, nil
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// release clears the in flight ID, remembering it if it was handled.
+func (d *dedup) release(id manifold.ID, handled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	close(d.inflight[id])
+	delete(d.inflight, id)
+
+	if !handled {
+		return
+	}
+
+	d.seen[id] = d.order.PushBack(id)
+	for d.order.Len() > d.size {
+		oldest := d.order.Front()
+		d.order.Remove(oldest)
+		delete(d.seen, oldest.Value.(manifold.ID))
+	}
+}
@@ -0,0 +1,324 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func eventJSON(t *testing.T, typ Type) []byte {
+	t.Helper()
+
+	id, _ := manifold.NewID(idtype.ActivityEvent)
+	return []byte(fmt.Sprintf(`{"id":%q,"type":"event","version":1,"body":{"type":%q,"data":{"reason":"gdpr"}}}`,
+		id, typ))
+}
+
+func TestMux_DispatchJSON(t *testing.T) {
+	ctx := context.Background()
+
+	var got []string
+
+	mux := NewMux()
+	mux.HandleAccountUpdated(func(_ context.Context, _ *Event, b *AccountUpdated) error {
+		got = append(got, "updated "+b.Data.Reason)
+		return nil
+	})
+	mux.Handle(TypeAccountStatusUpdated, func(_ context.Context, e *Event) error {
+		got = append(got, "status "+string(e.Body.Type()))
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		typ      Type
+		fallback bool
+		want     string
+		wantErr  error
+	}{
+		{name: "typed handler", typ: TypeAccountUpdated, want: "updated gdpr"},
+		{name: "event handler", typ: TypeAccountStatusUpdated, want: "status account.status.updated"},
+		{name: "unhandled type", typ: TypeOperationFailed, wantErr: ErrNoHandler},
+		{name: "unknown type", typ: "team.created", wantErr: ErrNoHandler},
+		{name: "unknown type with fallback", typ: "team.created", fallback: true, want: "fallback team.created"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			if tt.fallback {
+				mux.Fallback(HandlerFunc(func(_ context.Context, e *Event) error {
+					if _, ok := e.Body.(*RawBody); !ok {
+						t.Errorf("expected a RawBody for an unknown type, got %T", e.Body)
+					}
+					got = append(got, "fallback "+string(e.Body.Type()))
+					return nil
+				}))
+				defer mux.Fallback(nil)
+			}
+
+			err := mux.DispatchJSON(ctx, eventJSON(t, tt.typ))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Mux.DispatchJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != "" && (len(got) != 1 || got[0] != tt.want) {
+				t.Errorf("Mux.DispatchJSON() handled %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMux_Handle(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     Type
+		handler interface{}
+	}{
+		{
+			name:    "mismatched body",
+			typ:     TypeTeamCreated,
+			handler: func(context.Context, *Event, *OperationFailed) error { return nil },
+		},
+		{
+			name:    "typed handler for a built-in type",
+			typ:     TypeAccountUpdated,
+			handler: func(context.Context, *Event, *AccountUpdated) error { return nil },
+		},
+		{
+			name:    "unknown type",
+			typ:     "team.created",
+			handler: func(context.Context, *Event, *OperationFailed) error { return nil },
+		},
+		{
+			name:    "invalid signature",
+			typ:     TypeAccountUpdated,
+			handler: func(*AccountUpdated) {},
+		},
+		{
+			name:    "duplicate handler",
+			typ:     TypeOperationFailed,
+			handler: func(context.Context, *Event) error { return nil },
+		},
+	}
+
+	mux := NewMux()
+	mux.Handle(TypeOperationFailed, HandlerFunc(func(context.Context, *Event) error { return nil }))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Mux.Handle() expected a panic")
+				}
+			}()
+			mux.Handle(tt.typ, tt.handler)
+		})
+	}
+}
+
+func TestMux_Middleware(t *testing.T) {
+	ctx := context.Background()
+
+	var logs []string
+	calls := 0
+	fail := true
+
+	mux := NewMux()
+	mux.Use(
+		Logging(func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }),
+		Recovery(),
+		Dedup(10),
+	)
+	mux.HandleAccountUpdated(func(context.Context, *Event, *AccountUpdated) error {
+		calls++
+		if fail {
+			panic("boom")
+		}
+		return nil
+	})
+
+	b := eventJSON(t, TypeAccountUpdated)
+
+	var perr *PanicError
+	if err := mux.DispatchJSON(ctx, b); !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Fatalf("Mux.DispatchJSON() error = %v, want a PanicError", err)
+	}
+
+	fail = false
+	for i := 0; i < 2; i++ {
+		if err := mux.DispatchJSON(ctx, b); err != nil {
+			t.Fatalf("Mux.DispatchJSON() error = %v", err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("expected the handler to be called twice, got %d", calls)
+	}
+
+	if len(logs) != 3 || !strings.Contains(logs[0], "failed") || !strings.Contains(logs[1], "handled") {
+		t.Errorf("unexpected logs %v", logs)
+	}
+}
+
+func TestDedup_Eviction(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	h := Dedup(1)(HandlerFunc(func(context.Context, *Event) error {
+		calls++
+		return nil
+	}))
+
+	var evts []*Event
+	for i := 0; i < 2; i++ {
+		e := &Event{}
+		if err := json.Unmarshal(eventJSON(t, TypeAccountUpdated), e); err != nil {
+			t.Fatal(err)
+		}
+		evts = append(evts, e)
+	}
+
+	for _, e := range []*Event{evts[0], evts[0], evts[1], evts[0]} {
+		if err := h.HandleEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls with an evicted ID, got %d", calls)
+	}
+}
+
+func TestDedup_Concurrent(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	calls := 0
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	h := Dedup(10)(HandlerFunc(func(context.Context, *Event) error {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+
+		if first {
+			close(started)
+			<-unblock
+		}
+		return nil
+	}))
+
+	e := &Event{}
+	if err := json.Unmarshal(eventJSON(t, TypeAccountUpdated), e); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		h.HandleEvent(ctx, e)
+	}()
+	<-started
+	go func() {
+		defer wg.Done()
+		h.HandleEvent(ctx, e)
+	}()
+
+	close(unblock)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected the handler to be called once, got %d", calls)
+	}
+}
+
+func TestDedup_Cancelled(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	h := Dedup(10)(HandlerFunc(func(context.Context, *Event) error {
+		close(started)
+		<-unblock
+		return nil
+	}))
+
+	e := &Event{}
+	if err := json.Unmarshal(eventJSON(t, TypeAccountUpdated), e); err != nil {
+		t.Fatal(err)
+	}
+
+	go h.HandleEvent(context.Background(), e)
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := h.HandleEvent(ctx, e); err != context.Canceled {
+		t.Errorf("HandleEvent() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestLogging_NilBody(t *testing.T) {
+	var logs []string
+	mux := NewMux()
+	mux.Use(Logging(func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }))
+
+	if err := mux.Dispatch(context.Background(), &Event{}); err == nil {
+		t.Fatal("Mux.Dispatch() expected an error for an event without a body")
+	}
+
+	if len(logs) != 1 || !strings.Contains(logs[0], "<no body>") {
+		t.Errorf("unexpected logs %v", logs)
+	}
+}
+
+func TestMux_TypedHandlers(t *testing.T) {
+	ctx := context.Background()
+
+	var got []string
+
+	mux := NewMux()
+	mux.HandleOperationFailed(func(_ context.Context, _ *Event, b *OperationFailed) error {
+		got = append(got, "failed "+string(b.Type()))
+		return nil
+	})
+	mux.Handle(TypeTeamCreated, func(_ context.Context, _ *Event, b *TeamCreated) error {
+		got = append(got, "custom "+b.Data.Name)
+		return nil
+	})
+
+	failed := &OperationFailed{}
+	failed.SetType(string(TypeOperationFailed))
+
+	custom := &TeamCreated{}
+	custom.SetType(string(TypeTeamCreated))
+	custom.Data = &struct {
+		Name string `json:"name"`
+	}{Name: "manifold"}
+
+	for _, body := range []Body{failed, custom} {
+		if err := mux.Dispatch(ctx, &Event{Body: body}); err != nil {
+			t.Fatalf("Mux.Dispatch() error = %v", err)
+		}
+	}
+
+	if len(got) != 2 || got[0] != "failed operation.failed" || got[1] != "custom manifold" {
+		t.Errorf("Mux.Dispatch() handled %v", got)
+	}
+
+	// A body not matching its type is rejected instead of being handed to
+	// the typed handler.
+	mismatched := &TeamCreated{}
+	mismatched.SetType(string(TypeOperationFailed))
+	if err := mux.Dispatch(ctx, &Event{Body: mismatched}); err == nil {
+		t.Error("Mux.Dispatch() expected an error for a mismatched body")
+	}
+}
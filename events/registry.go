@@ -0,0 +1,116 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/errors"
+)
+
+var bodies = struct {
+	sync.RWMutex
+	factories map[Type]func() Body
+}{factories: map[Type]func() Body{}}
+
+func init() {
+	RegisterBody(TypeOperationProvisioned, func() Body { return &OperationProvisioned{} })
+	RegisterBody(TypeOperationDeprovisioned, func() Body { return &OperationDeprovisioned{} })
+	RegisterBody(TypeOperationResized, func() Body { return &OperationResized{} })
+	RegisterBody(TypeResourceProjectChanged, func() Body { return &ResourceProjectChanged{} })
+	RegisterBody(TypeResourceOwnerChanged, func() Body { return &ResourceOwnerChanged{} })
+	RegisterBody(TypeOperationFailed, func() Body { return &OperationFailed{} })
+	RegisterBody(TypeResourceMeasuresAdded, func() Body { return &ResourceMeasuresAdded{} })
+	RegisterBody(TypeResourceMeasuresFailed, func() Body { return &ResourceMeasuresFailed{} })
+	RegisterBody(TypeAccountStatusUpdated, func() Body { return &AccountStatusUpdated{} })
+	RegisterBody(TypeAccountUpdated, func() Body { return &AccountUpdated{} })
+}
+
+// RegisterBody registers the body of an event type, allowing events of that
+// type to be decoded, validated and handled by a Mux. Ideally, call this from
+// your package's init function.
+//
+// RegisterBody panics if the type is already registered with a body of another
+// Go type.
+func RegisterBody(t Type, fn func() Body) {
+	bodies.Lock()
+	defer bodies.Unlock()
+
+	if existing, ok := bodies.factories[t]; ok {
+		prev, next := reflect.TypeOf(existing()), reflect.TypeOf(fn())
+		if prev != next {
+			panic(fmt.Sprintf("events: type %q already registered with %s (trying to register %s)", t, prev, next))
+		}
+		return
+	}
+
+	bodies.factories[t] = fn
+}
+
+// newBody returns an empty body for the event type.
+func newBody(t Type) (Body, bool) {
+	bodies.RLock()
+	fn, ok := bodies.factories[t]
+	bodies.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return fn(), true
+}
+
+// validateBodyType checks that the body is the one registered for its type.
+// RawBodies are accepted for unknown types only.
+func validateBodyType(body Body) error {
+	if body == nil {
+		return manifold.NewError(errors.BadRequestError, "Expected a body")
+	}
+
+	expected, ok := newBody(body.Type())
+	if !ok {
+		if _, raw := body.(*RawBody); raw {
+			return nil
+		}
+		return manifold.NewError(errors.BadRequestError,
+			fmt.Sprintf("Unrecognized event type %q", body.Type()))
+	}
+
+	if reflect.TypeOf(body) != reflect.TypeOf(expected) {
+		return manifold.NewError(errors.BadRequestError,
+			fmt.Sprintf("Expected a %T body for event type %q, got %T", expected, body.Type(), body))
+	}
+
+	return nil
+}
+
+// RawBody holds the body of an event of an unknown type, decoded by
+// UnmarshalLenient. Its JSON is preserved as is, so the event can be passed on
+// without dropping the fields this package doesn't know about.
+type RawBody struct {
+	BaseBody
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, decoding the base
+// body and keeping the raw JSON.
+func (b *RawBody) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.BaseBody); err != nil {
+		return err
+	}
+
+	b.Raw = append(json.RawMessage{}, data...)
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, returning the raw JSON
+// of the body. Changes made through the BaseBody setters are not encoded.
+func (b *RawBody) MarshalJSON() ([]byte, error) {
+	if b.Raw == nil {
+		return json.Marshal(&b.BaseBody)
+	}
+
+	return b.Raw, nil
+}
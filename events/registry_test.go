@@ -0,0 +1,120 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const TypeTeamCreated Type = "test.team.created"
+
+type TeamCreated struct {
+	BaseBody
+	Data *struct {
+		Name string `json:"name"`
+	} `json:"data"`
+}
+
+func init() {
+	RegisterBody(TypeTeamCreated, func() Body { return &TeamCreated{} })
+}
+
+func TestRegisterBody(t *testing.T) {
+	t.Run("with a custom type", func(t *testing.T) {
+		b := []byte(`{"id":"` + newEventID(t) + `","type":"event","version":1,"body":{"type":"test.team.created","data":{"name":"manifold"}}}`)
+
+		e := &Event{}
+		if err := json.Unmarshal(b, e); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		body, ok := e.Body.(*TeamCreated)
+		if !ok || body.Data.Name != "manifold" {
+			t.Errorf("expected a TeamCreated body, got %#v", e.Body)
+		}
+		if err := e.Validate(nil); err != nil {
+			t.Errorf("Event.Validate() error = %v", err)
+		}
+	})
+
+	t.Run("with the same body", func(t *testing.T) {
+		RegisterBody(TypeTeamCreated, func() Body { return &TeamCreated{} })
+	})
+
+	t.Run("with a conflicting body", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("RegisterBody() expected a panic")
+			}
+		}()
+		RegisterBody(TypeAccountUpdated, func() Body { return &TeamCreated{} })
+	})
+}
+
+func TestUnmarshalLenient(t *testing.T) {
+	body := `{"type":"team.renamed","data":{"old":"a","new":"b"}}`
+	b := []byte(`{"id":"` + newEventID(t) + `","type":"event","version":1,"body":` + body + `}`)
+
+	var ute *UnknownTypeError
+	if err := json.Unmarshal(b, &Event{}); !errors.As(err, &ute) || ute.Type != "team.renamed" {
+		t.Errorf("json.Unmarshal() error = %v, want an UnknownTypeError", err)
+	}
+
+	e := &Event{}
+	if err := UnmarshalLenient(b, e); err != nil {
+		t.Fatalf("UnmarshalLenient() error = %v", err)
+	}
+
+	raw, ok := e.Body.(*RawBody)
+	if !ok || raw.Type() != "team.renamed" {
+		t.Fatalf("expected a RawBody, got %#v", e.Body)
+	}
+	if err := e.Validate(nil); err != nil {
+		t.Errorf("Event.Validate() error = %v", err)
+	}
+
+	out, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !bytes.Contains(out, []byte(`"body":`+body)) {
+		t.Errorf("json.Marshal() = %s, want the body preserved", out)
+	}
+}
+
+func TestEvent_ValidateBodyType(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    Body
+		wantErr bool
+	}{
+		{name: "registered body", body: &AccountUpdated{BaseBody: BaseBody{EventType: TypeAccountUpdated}}},
+		{name: "mismatched body", body: &AccountUpdated{BaseBody: BaseBody{EventType: TypeOperationFailed}}, wantErr: true},
+		{name: "unknown type", body: &BaseBody{EventType: "team.renamed"}, wantErr: true},
+		{name: "raw body", body: &RawBody{BaseBody: BaseBody{EventType: "team.renamed"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.Body = tt.body
+
+			if err := e.Validate(nil); (err != nil) != tt.wantErr {
+				t.Errorf("Event.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newEventID(t *testing.T) string {
+	t.Helper()
+
+	e, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e.ID.String()
+}
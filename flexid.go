@@ -138,6 +138,10 @@ type Identifier interface {
 	IsEmpty() bool
 	// Equals checks the equality of this Identifier against another
 	Equals(Identifier) bool
+	// URN returns the Identifier as a `urn:DOMAIN:CLASS:ID` URN
+	URN() string
+	// URL returns the Identifier as a `https://DOMAIN/CLASS/ID` URL
+	URL() string
 }
 
 // NewFlexID constructs a FlexID from the provided Domain, Class, and ID parts
@@ -185,8 +189,8 @@ func (id FlexID) String() string {
 		pathSeperator, id.ID())
 }
 
-// Validate implements the Validate interface for goswagger
-//  which always succeeds because the ID is already parsed
+// Validate implements the Validate interface for goswagger. On top of the
+//  format of each part, it applies the rules registered for the Domain
 func (id FlexID) Validate(v strfmt.Registry) error {
 	if err := id.Domain().Validate(v); err != nil {
 		return err
@@ -198,7 +202,7 @@ func (id FlexID) Validate(v strfmt.Registry) error {
 		return err
 	}
 
-	return nil
+	return id.validateDomainRules()
 }
 
 // MarshalText implements the encoding.TextMarshaler interface
@@ -301,13 +305,13 @@ func (id FlexID) IsEmpty() bool {
 }
 
 // Equals is implemented to allow for easy comparison of FlexIDs to IDs using the
-//  Identifier interface
+//  Identifier interface. The canonical forms of the IDs are compared
 func (id FlexID) Equals(oid Identifier) bool {
 	if oid == nil {
 		return false
 	}
 	fid := oid.AsFlexID()
-	return fid != nil && *fid == id
+	return fid != nil && fid.Canonical() == id.Canonical()
 }
 
 // Ensure interface adherence
@@ -9,6 +9,7 @@ require (
 	github.com/manifoldco/go-base64 v1.0.3
 	github.com/pkg/errors v0.9.0
 	golang.org/x/crypto v0.0.0-20200109152110-61a87790db17
+	gopkg.in/yaml.v2 v2.2.4
 )
 
 go 1.13
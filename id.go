@@ -2,27 +2,53 @@ package manifold
 
 import (
 	"crypto/rand"
+	"encoding/base32"
 	"encoding/json"
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/go-openapi/strfmt"
 
 	"github.com/dchest/blake2b"
 
-	"github.com/manifoldco/go-base32"
-
 	"github.com/manifoldco/go-manifold/idtype"
 )
 
 const (
 	idVersion  = 0x1
 	byteLength = 18
+
+	// sortableIDVersion is the version of IDs whose payload starts with a
+	// millisecond timestamp, see NewSortableID.
+	sortableIDVersion = 0x2
+	timestampLength   = 6
+
+	// encodedLength is the length of an ID encoded in unpadded base32
+	encodedLength = 29
+
+	// base32Alphabet is the alphabet used by github.com/manifoldco/go-base32
+	base32Alphabet = "0123456789abcdefghjkmnpqrtuvwxyz"
 )
 
 // Comparator for empty IDs
 var emptyID [byteLength]byte
 
+var errIDLength = errors.New("Incorrect length for id")
+
+// base32DecodeMap maps characters of the base32 alphabet to their value, and
+// every other character to 0xFF.
+var base32DecodeMap [256]byte
+
+func init() {
+	for i := range base32DecodeMap {
+		base32DecodeMap[i] = 0xFF
+	}
+	for i := 0; i < len(base32Alphabet); i++ {
+		base32DecodeMap[base32Alphabet[i]] = byte(i)
+	}
+}
+
 // Identifiable is the interface implemented by objects that can be given
 // IDs.
 type Identifiable interface {
@@ -108,6 +134,46 @@ func MustNewID(t idtype.Type) ID {
 	return id
 }
 
+// NewSortableID returns a new time-sortable ID for a Mutable idtype.
+//
+// The payload of the ID starts with the current Unix time in milliseconds,
+// as 6 big-endian bytes, followed by 10 random bytes. IDs of the same type
+// created at different times sort in creation order, both as bytes and in
+// their base32 form. The time can be read back with Timestamp.
+func NewSortableID(t idtype.Type) (ID, error) {
+	return newSortableID(t, time.Now())
+}
+
+// MustNewSortableID returns a new time-sortable ID for a Mutable idtype.
+// It panics if NewSortableID returns an error
+func MustNewSortableID(t idtype.Type) ID {
+	id, err := NewSortableID(t)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func newSortableID(t idtype.Type, now time.Time) (ID, error) {
+	if !t.Mutable() {
+		return ID{}, errors.New("Cannot generate ID for non-mutable type")
+	}
+
+	id := ID{sortableIDVersion<<4 | t.Upper(), t.Lower()}
+
+	ms := uint64(now.UnixNano() / int64(time.Millisecond))
+	for i := 0; i < timestampLength; i++ {
+		id[2+i] = byte(ms >> (8 * uint(timestampLength-1-i)))
+	}
+
+	_, err := rand.Read(id[2+timestampLength:])
+	if err != nil {
+		return ID{}, err
+	}
+
+	return id, nil
+}
+
 // NewImmutableID returns a new signed ID for an immutable object.
 //
 // sig should be a registry.Signature type
@@ -151,30 +217,102 @@ func MustNewImmutableID(obj Immutable, sig interface{}) ID {
 
 // DecodeIDFromString returns an ID that is stored in the given string.
 func DecodeIDFromString(value string) (ID, error) {
-	buf, err := decodeFromByte([]byte(value))
-	if err != nil {
-		return ID{}, err
+	if len(value) != encodedLength {
+		return ID{}, errIDLength
 	}
 
+	// The conversion doesn't allocate as the bytes don't escape and are
+	// short enough to live on the stack.
+	return DecodeID([]byte(value))
+}
+
+// DecodeID returns the ID stored in the given bytes, in their base32 text
+// form. The bytes are left untouched and decoded directly into the returned
+// ID, without allocating.
+func DecodeID(b []byte) (ID, error) {
 	id := ID{}
-	copy(id[:], buf)
+	if len(b) != encodedLength {
+		return id, errIDLength
+	}
+
+	var acc uint32
+	var bits uint
+	n := 0
+	for i, c := range b {
+		v := base32DecodeMap[c]
+		if v == 0xFF {
+			return ID{}, base32.CorruptInputError(i)
+		}
+
+		acc = acc<<5 | uint32(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			id[n] = byte(acc >> bits)
+			n++
+		}
+	}
+
 	return id, nil
 }
 
+// Timestamp returns the time a sortable ID was created at, with millisecond
+// precision. The boolean is false for IDs which don't hold a timestamp.
+func (id ID) Timestamp() (time.Time, bool) {
+	if id[0]>>4 != sortableIDVersion {
+		return time.Time{}, false
+	}
+
+	var ms int64
+	for _, b := range id[2 : 2+timestampLength] {
+		ms = ms<<8 | int64(b)
+	}
+
+	return time.Unix(0, ms*int64(time.Millisecond)), true
+}
+
 // Type returns the idtype.Type encoded object type represented by this ID.
 func (id ID) Type() idtype.Type {
 	return idtype.Decode(id[0]&0x0F, id[1])
 }
 
 func (id ID) String() string {
-	return base32.EncodeToString(id[:])
+	var buf [encodedLength]byte
+	return string(id.AppendString(buf[:0]))
+}
+
+// AppendString appends the unpadded base32 form of the ID to dst and returns
+// the extended buffer. It doesn't allocate when dst has enough capacity.
+func (id ID) AppendString(dst []byte) []byte {
+	var acc uint32
+	var bits uint
+	for _, b := range id {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			dst = append(dst, base32Alphabet[(acc>>bits)&0x1F])
+		}
+	}
+
+	if bits > 0 {
+		dst = append(dst, base32Alphabet[(acc<<(5-bits))&0x1F])
+	}
+
+	return dst
+}
+
+// AppendText implements the encoding.TextAppender interface for IDs. It is
+// the same as AppendString, and never returns an error.
+func (id ID) AppendText(dst []byte) ([]byte, error) {
+	return id.AppendString(dst), nil
 }
 
 // MarshalText implements the encoding.TextMarshaler interface for IDs.
 //
 // IDs are encoded in unpadded base32.
 func (id ID) MarshalText() ([]byte, error) {
-	return []byte(id.String()), nil
+	return id.AppendString(make([]byte, 0, encodedLength)), nil
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface for IDs.
@@ -183,27 +321,15 @@ func (id *ID) UnmarshalText(b []byte) error {
 }
 
 func (id *ID) fillID(raw []byte) error {
-	out, err := decodeFromByte(raw)
+	out, err := DecodeID(raw)
 	if err != nil {
 		return err
 	}
 
-	copy(id[:], out)
+	*id = out
 	return nil
 }
 
-func decodeFromByte(raw []byte) ([]byte, error) {
-	out, err := base32.DecodeString(string(raw))
-	if err != nil {
-		return nil, err
-	}
-	if len(out) != byteLength {
-		return nil, errors.New("Incorrect length for id")
-	}
-
-	return out, nil
-}
-
 // Validate implements the Validate interface for goswagger.
 // We know that if the value has successfully parsed, it is valid, so no action
 // is required.
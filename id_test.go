@@ -0,0 +1,209 @@
+package manifold
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-base32"
+
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func TestID_AppendString(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		var id ID
+		rand.Read(id[:])
+
+		want := base32.EncodeToString(id[:])
+		if got := string(id.AppendString(nil)); got != want {
+			t.Fatalf("ID.AppendString() = %s, want %s", got, want)
+		}
+		if got := id.String(); got != want {
+			t.Fatalf("ID.String() = %s, want %s", got, want)
+		}
+
+		got, err := DecodeID([]byte(want))
+		if err != nil || got != id {
+			t.Fatalf("DecodeID() = %s, %v, want %s", got, err, id)
+		}
+	}
+
+	t.Run("appends to the buffer", func(t *testing.T) {
+		id := MustNewID(idtype.User)
+		got, err := id.AppendText([]byte("id="))
+		if err != nil {
+			t.Fatalf("ID.AppendText() error = %v", err)
+		}
+		if want := "id=" + id.String(); string(got) != want {
+			t.Errorf("ID.AppendText() = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestDecodeID(t *testing.T) {
+	id := MustNewID(idtype.User)
+	valid := id.String()
+
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{name: "valid", in: valid},
+		{name: "too short", in: valid[1:], wantErr: "Incorrect length for id"},
+		{name: "too long", in: valid + "0", wantErr: "Incorrect length for id"},
+		{name: "invalid character", in: "i" + valid[1:], wantErr: "illegal base32 data at input byte 0"},
+		{name: "uppercase", in: valid[:28] + "Z", wantErr: "illegal base32 data at input byte 28"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeIDFromString(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("DecodeIDFromString() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil || got != id {
+				t.Errorf("DecodeIDFromString() = %s, %v, want %s", got, err, id)
+			}
+		})
+	}
+}
+
+func TestID_RoundTripAllocs(t *testing.T) {
+	id := MustNewID(idtype.User)
+	buf := make([]byte, 0, encodedLength)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = id.AppendString(buf[:0])
+		if _, err := DecodeID(buf); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected the round trip not to allocate, got %v allocations", allocs)
+	}
+}
+
+func BenchmarkID_String(b *testing.B) {
+	id := MustNewID(idtype.User)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = id.String()
+	}
+}
+
+func BenchmarkID_AppendString(b *testing.B) {
+	id := MustNewID(idtype.User)
+	buf := make([]byte, 0, encodedLength)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = id.AppendString(buf[:0])
+	}
+}
+
+func BenchmarkDecodeID(b *testing.B) {
+	raw := []byte(MustNewID(idtype.User).String())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeID(raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkID_RoundTrip(b *testing.B) {
+	id := MustNewID(idtype.User)
+	buf := make([]byte, 0, encodedLength)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = id.AppendString(buf[:0])
+		if _, err := DecodeID(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if !bytes.Equal(buf, []byte(id.String())) {
+		b.Fatal("Expected the encoding to be stable")
+	}
+}
+
+func BenchmarkBase32_RoundTrip(b *testing.B) {
+	id := MustNewID(idtype.User)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := base32.DecodeString(base32.EncodeToString(id[:])); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNewSortableID(t *testing.T) {
+	now := time.Date(2019, 10, 18, 12, 30, 15, int(250*time.Millisecond), time.UTC)
+
+	t.Run("holds the timestamp", func(t *testing.T) {
+		id, err := newSortableID(idtype.Resource, now)
+		if err != nil {
+			t.Fatalf("newSortableID() error = %v", err)
+		}
+
+		ts, ok := id.Timestamp()
+		if !ok || !ts.Equal(now) {
+			t.Errorf("ID.Timestamp() = %s, %v, want %s", ts, ok, now)
+		}
+		if id.Type() != idtype.Resource {
+			t.Errorf("ID.Type() = %s, want %s", id.Type(), idtype.Resource)
+		}
+	})
+
+	t.Run("sorts by creation time", func(t *testing.T) {
+		prev := ""
+		for i := 0; i < 100; i++ {
+			id, err := newSortableID(idtype.Resource, now.Add(time.Duration(i)*time.Millisecond))
+			if err != nil {
+				t.Fatalf("newSortableID() error = %v", err)
+			}
+
+			s := id.String()
+			if s <= prev {
+				t.Fatalf("Expected '%s' to sort after '%s'", s, prev)
+			}
+			prev = s
+		}
+	})
+
+	t.Run("round trips", func(t *testing.T) {
+		id := MustNewSortableID(idtype.User)
+
+		got, err := DecodeIDFromString(id.String())
+		if err != nil || got != id {
+			t.Fatalf("DecodeIDFromString() = %s, %v, want %s", got, err, id)
+		}
+
+		mid, err := id.AsFlexID().AsManifoldID()
+		if err != nil || *mid != id {
+			t.Fatalf("FlexID.AsManifoldID() = %v, %v, want %s", mid, err, id)
+		}
+	})
+
+	t.Run("with a non-mutable type", func(t *testing.T) {
+		if _, err := NewSortableID(idtype.Invoice); err == nil {
+			t.Fatal("NewSortableID() expected an error for an immutable type")
+		}
+	})
+
+	t.Run("with a random ID", func(t *testing.T) {
+		if _, ok := MustNewID(idtype.User).Timestamp(); ok {
+			t.Error("Expected a random ID not to hold a timestamp")
+		}
+	})
+}
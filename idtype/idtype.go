@@ -3,8 +3,11 @@ package idtype
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // Type is the enumerated list of all registered types, global within the
@@ -88,6 +91,20 @@ var (
 	TypeOverflow = 0x1000
 )
 
+// groupSize is the number of types in a group, as documented above.
+const groupSize = 0x64
+
+var (
+	// ErrTypeConflict is returned when registering a Type which is already
+	// registered with a different definition.
+	ErrTypeConflict = errors.New("type already registered")
+	// ErrNameConflict is returned when registering a name or plural which is
+	// already used by another Type.
+	ErrNameConflict = errors.New("type name already registered")
+	// ErrTypeRange is returned when a Type doesn't follow the range policy.
+	ErrTypeRange = errors.New("type outside of the allowed range")
+)
+
 // Upper returns the upper byte of the type
 func (t Type) Upper() byte {
 	o := make([]byte, 2)
@@ -130,12 +147,7 @@ func (t Type) Name() string {
 // Plural returns the plural name of an instance of this type
 func (t Type) Plural() string {
 	defn := getDefn(t)
-
-	if defn.plural != nil {
-		return *defn.plural
-	}
-
-	return fmt.Sprintf("%ss", defn.name)
+	return defn.pluralName()
 }
 
 // String returns the string representation of the primitive type
@@ -149,9 +161,12 @@ func Decode(upper, lower byte) Type {
 }
 
 func getDefn(t Type) definition {
-	defn, ok := definitions[t]
+	registry.RLock()
+	defn, ok := registry.definitions[t]
+	registry.RUnlock()
+
 	if !ok {
-		panic("Type does not have a definition: " + string(t))
+		panic(fmt.Sprintf("Type does not have a definition: %#x", uint16(t)))
 	}
 
 	return defn
@@ -169,25 +184,110 @@ func getDefn(t Type) definition {
 //
 // Register panics if it is called twice with the same Type.
 func Register(typ Type, mutable bool, name string, options ...Option) {
-	def := definition{
-		mutable: mutable,
-		name:    name,
-	}
+	def := newDefinition(mutable, name, options)
 
-	for _, item := range options {
-		item(&def)
-	}
+	registry.Lock()
+	defer registry.Unlock()
 
 	// types should not be registered multiple times, but we ignore it if the
 	// subsequent registrations are exactly equal to the first one.
-	if d, ok := definitions[typ]; ok {
-		if d != def {
-			panic(fmt.Sprintf("Type %d already registered as %v (trying to register %v)", typ, d, def))
+	if err := register(typ, def); err != nil {
+		panic(err.Error())
+	}
+}
+
+// TryRegister registers a type like Register, but returns an error instead of
+// panicking. It is meant for plugins registering their own types, and is
+// stricter than Register:
+//
+// - ErrTypeConflict is returned if the type is registered differently.
+// - ErrNameConflict is returned if the name or plural is used by another type.
+// - ErrTypeRange is returned if the type doesn't pass CheckRange.
+func TryRegister(typ Type, mutable bool, name string, options ...Option) error {
+	def := newDefinition(mutable, name, options)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if d, ok := registry.definitions[typ]; ok && d.equal(def) {
+		return nil
+	}
+
+	if err := checkRange(typ); err != nil {
+		return err
+	}
+
+	for _, n := range []string{def.name, def.pluralName()} {
+		if t, ok := registry.names[n]; ok && t != typ {
+			return fmt.Errorf("%q is used by type %#x: %w", n, uint16(t), ErrNameConflict)
+		}
+		if t, ok := registry.plurals[n]; ok && t != typ {
+			return fmt.Errorf("%q is used by type %#x: %w", n, uint16(t), ErrNameConflict)
 		}
-		return
 	}
 
-	definitions[typ] = def
+	return register(typ, def)
+}
+
+// CheckRange checks that a new type follows the range policy documented on the
+// Type values:
+//
+// - types must fit in 12 bits, below TypeOverflow.
+// - types from ManifoldInternalReserved onwards are reserved for Manifold.
+// - types within a group of 100 are added sequentially, so a type must either
+// start a group (or a group of 50), or follow a registered type.
+//
+// The types registered by this package, some of which predate the policy like
+// ActivityEventJob, always pass.
+func CheckRange(typ Type) error {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return checkRange(typ)
+}
+
+func checkRange(typ Type) error {
+	switch {
+	case typ >= Type(TypeOverflow):
+		return fmt.Errorf("type %#x overflows %#x: %w", uint16(typ), TypeOverflow, ErrTypeRange)
+	case typ >= ManifoldInternalReserved:
+		return fmt.Errorf("type %#x is reserved for Manifold internal use: %w", uint16(typ), ErrTypeRange)
+	}
+
+	offset := typ % groupSize
+	if offset == 0 || offset == groupSize/2 {
+		return nil
+	}
+
+	if _, ok := registry.definitions[typ-1]; !ok && !registry.builtin[typ] {
+		return fmt.Errorf("type %#x does not follow a registered type in group %#x: %w",
+			uint16(typ), uint16(typ-offset), ErrTypeRange)
+	}
+
+	return nil
+}
+
+// register adds the definition to the registry, which must be locked.
+func register(typ Type, def definition) error {
+	if d, ok := registry.definitions[typ]; ok {
+		if !d.equal(def) {
+			return fmt.Errorf("Type %d already registered as %v (trying to register %v): %w",
+				typ, d, def, ErrTypeConflict)
+		}
+		return nil
+	}
+
+	registry.definitions[typ] = def
+
+	// the first type registered with a name is the one returned by lookups.
+	if _, ok := registry.names[def.name]; !ok {
+		registry.names[def.name] = typ
+	}
+	if _, ok := registry.plurals[def.pluralName()]; !ok {
+		registry.plurals[def.pluralName()] = typ
+	}
+
+	return nil
 }
 
 // Option is a function that can update the value ofa Type struct
@@ -201,18 +301,84 @@ func WithPlural(name string) Option {
 }
 
 // TypeFromString will return the type from a string interpretation of the type.
-// If the type is not found, this will panic.
+// If the type is not found, this will panic. Use Lookup to check first.
 func TypeFromString(str string) Type {
-	for t, d := range definitions {
-		if d.name == str {
-			return t
+	t, ok := Lookup(str)
+	if !ok {
+		panic("Type not registered")
+	}
+
+	return t
+}
+
+// Lookup returns the type registered with the name, and whether it was found.
+func Lookup(name string) (Type, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	t, ok := registry.names[name]
+	return t, ok
+}
+
+// LookupPlural returns the type registered with the plural name, and whether
+// it was found.
+func LookupPlural(plural string) (Type, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	t, ok := registry.plurals[plural]
+	return t, ok
+}
+
+// Info describes a registered type.
+type Info struct {
+	Type       Type
+	Name       string
+	Plural     string
+	Collection string
+	Mutable    bool
+}
+
+// All returns the description of every registered type, ordered by Type.
+func All() []Info {
+	registry.RLock()
+	types := make([]Type, 0, len(registry.definitions))
+	for t := range registry.definitions {
+		types = append(types, t)
+	}
+	registry.RUnlock()
+
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	infos := make([]Info, len(types))
+	for i, t := range types {
+		infos[i] = Info{
+			Type:       t,
+			Name:       t.Name(),
+			Plural:     t.Plural(),
+			Collection: t.Collection(),
+			Mutable:    t.Mutable(),
 		}
 	}
 
-	panic("Type not registered")
+	return infos
 }
 
-var definitions = map[Type]definition{}
+var registry = struct {
+	sync.RWMutex
+	definitions map[Type]definition
+	names       map[string]Type
+	plurals     map[string]Type
+
+	// builtin holds the types registered by init, which are exempt from
+	// the range policy.
+	builtin map[Type]bool
+}{
+	definitions: map[Type]definition{},
+	names:       map[string]Type{},
+	plurals:     map[string]Type{},
+	builtin:     map[Type]bool{},
+}
 
 type definition struct {
 	mutable bool
@@ -220,6 +386,33 @@ type definition struct {
 	plural  *string
 }
 
+func newDefinition(mutable bool, name string, options []Option) definition {
+	def := definition{
+		mutable: mutable,
+		name:    name,
+	}
+
+	for _, item := range options {
+		item(&def)
+	}
+
+	return def
+}
+
+func (d definition) pluralName() string {
+	if d.plural != nil {
+		return *d.plural
+	}
+
+	return d.name + "s"
+}
+
+// equal compares the definitions, including the value of their plurals.
+func (d definition) equal(o definition) bool {
+	return d.mutable == o.mutable && d.name == o.name &&
+		(d.plural == nil) == (o.plural == nil) && d.pluralName() == o.pluralName()
+}
+
 func init() {
 	Register(User, true, "user")
 	Register(ForgotPasswordToken, true, "forgot_password_token")
@@ -259,4 +452,8 @@ func init() {
 
 	Register(ActivityEventJob, true, "event_job")
 	Register(ActivityEvent, true, "event")
+
+	for t := range registry.definitions {
+		registry.builtin[t] = true
+	}
 }
@@ -1,6 +1,7 @@
 package idtype_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/manifoldco/go-manifold/idtype"
@@ -150,3 +151,96 @@ func Test_Register(t *testing.T) {
 		})
 	}
 }
+
+func TestLookup(t *testing.T) {
+	if typ, ok := idtype.Lookup("category"); !ok || typ != idtype.Category {
+		t.Errorf("Lookup() = %v, %v, want %v", typ, ok, idtype.Category)
+	}
+
+	if typ, ok := idtype.LookupPlural("categories"); !ok || typ != idtype.Category {
+		t.Errorf("LookupPlural() = %v, %v, want %v", typ, ok, idtype.Category)
+	}
+
+	if _, ok := idtype.Lookup("non-existing"); ok {
+		t.Error("Lookup() expected no type for an unregistered name")
+	}
+}
+
+func TestAll(t *testing.T) {
+	infos := idtype.All()
+
+	for i := 1; i < len(infos); i++ {
+		if infos[i-1].Type >= infos[i].Type {
+			t.Fatalf("All() is not ordered by type at index %d", i)
+		}
+	}
+
+	for _, info := range infos {
+		if info.Type != idtype.Invoice {
+			continue
+		}
+
+		want := idtype.Info{
+			Type:       idtype.Invoice,
+			Name:       "invoice",
+			Plural:     "invoices",
+			Collection: "invoices",
+			Mutable:    false,
+		}
+		if info != want {
+			t.Errorf("All() invoice = %+v, want %+v", info, want)
+		}
+		return
+	}
+
+	t.Error("All() did not include invoices")
+}
+
+func TestCheckRange(t *testing.T) {
+	for _, info := range idtype.All() {
+		// Manifold registers its internal types without following the policy.
+		if info.Type >= idtype.ManifoldInternalReserved {
+			continue
+		}
+
+		if err := idtype.CheckRange(info.Type); err != nil {
+			t.Errorf("CheckRange(%s) error = %v", info.Name, err)
+		}
+	}
+}
+
+func TestTryRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     idtype.Type
+		typName string
+		opts    []idtype.Option
+		wantErr error
+	}{
+		{name: "new group", typ: 0x7D0, typName: "widget"},
+		{name: "same registration", typ: 0x7D0, typName: "widget"},
+		{name: "next in group", typ: 0x7D1, typName: "gadget"},
+		{name: "half group", typ: 0x802, typName: "gizmo"},
+		{name: "gap in group", typ: 0x7D3, typName: "doohickey", wantErr: idtype.ErrTypeRange},
+		{name: "offset in an empty group", typ: 0x839, typName: "thingamajig", wantErr: idtype.ErrTypeRange},
+		{name: "reserved range", typ: idtype.ManifoldInternalReserved, typName: "secret", wantErr: idtype.ErrTypeRange},
+		{name: "overflow", typ: idtype.Type(idtype.TypeOverflow), typName: "overflow", wantErr: idtype.ErrTypeRange},
+		{name: "type conflict", typ: 0x7D0, typName: "sprocket", wantErr: idtype.ErrTypeConflict},
+		{name: "name conflict", typ: 0x7D2, typName: "user", wantErr: idtype.ErrNameConflict},
+		{
+			name:    "plural conflict",
+			typ:     0x7D2,
+			typName: "person",
+			opts:    []idtype.Option{idtype.WithPlural("users")},
+			wantErr: idtype.ErrNameConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := idtype.TryRegister(tt.typ, true, tt.typName, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TryRegister() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package manifold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/go-manifold/errors"
+)
+
+var (
+	// ErrImmutableIDTypeMismatch is returned when the type encoded in the ID
+	//  of an Immutable object doesn't match the type of the object.
+	ErrImmutableIDTypeMismatch = NewError(errors.BadRequestError,
+		"Invalid Immutable ID, the ID type does not match the object type")
+	// ErrImmutableIDContentMismatch is returned when the ID of an Immutable
+	//  object doesn't match its content and signature, meaning either of them
+	//  was modified after the ID was derived.
+	ErrImmutableIDContentMismatch = NewError(errors.BadRequestError,
+		"Invalid Immutable ID, the ID does not match the object content")
+)
+
+// VerifyImmutableID checks that the ID of the Immutable object is the one
+// NewImmutableID derives from its version, body and signature.
+//
+// ErrImmutableIDTypeMismatch is returned when the ID is for another type of
+// object, and ErrImmutableIDContentMismatch when the object or its signature
+// don't match the ID.
+func VerifyImmutableID(obj Immutable, sig interface{}) error {
+	id := obj.GetID()
+	if id.Type() != obj.Type() {
+		return ErrImmutableIDTypeMismatch
+	}
+
+	expected, err := NewImmutableID(obj, sig)
+	if err != nil {
+		return err
+	}
+
+	if id != expected {
+		return ErrImmutableIDContentMismatch
+	}
+
+	return nil
+}
+
+// ImmutableIDError describes why the ID of an object in a batch failed
+// verification.
+type ImmutableIDError struct {
+	Index int
+	ID    ID
+	Err   error
+}
+
+func (e *ImmutableIDError) Error() string {
+	return fmt.Sprintf("object %d (%s): %s", e.Index, e.ID, e.Err)
+}
+
+// ImmutableIDErrors lists every object of a batch that failed verification.
+type ImmutableIDErrors []*ImmutableIDError
+
+func (e ImmutableIDErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d invalid immutable IDs: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// VerifyImmutableIDs verifies the IDs of a batch of Immutable objects, the
+// signature of each object being at the same index in sigs. Every object is
+// verified, and the failures are returned as ImmutableIDErrors.
+func VerifyImmutableIDs(objs []Immutable, sigs []interface{}) error {
+	if len(objs) != len(sigs) {
+		return fmt.Errorf("expected a signature for each of the %d objects, got %d", len(objs), len(sigs))
+	}
+
+	var errs ImmutableIDErrors
+	for i, obj := range objs {
+		if err := VerifyImmutableID(obj, sigs[i]); err != nil {
+			errs = append(errs, &ImmutableIDError{Index: i, ID: obj.GetID(), Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package manifold
+
+import (
+	"testing"
+
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+type testInvoice struct {
+	ID   ID
+	Body struct {
+		Amount int `json:"amount"`
+	}
+}
+
+func (i *testInvoice) GetID() ID            { return i.ID }
+func (i *testInvoice) Version() int         { return 1 }
+func (i *testInvoice) Type() idtype.Type    { return idtype.Invoice }
+func (i *testInvoice) GetBody() interface{} { return i.Body }
+func (i *testInvoice) Immutable()           {}
+
+func newTestInvoice(amount int, sig interface{}) *testInvoice {
+	inv := &testInvoice{}
+	inv.Body.Amount = amount
+	inv.ID = MustNewImmutableID(inv, sig)
+	return inv
+}
+
+func TestVerifyImmutableID(t *testing.T) {
+	sig := map[string]string{"value": "signed"}
+
+	tests := []struct {
+		name    string
+		obj     func() *testInvoice
+		sig     interface{}
+		wantErr error
+	}{
+		{
+			name: "valid",
+			obj:  func() *testInvoice { return newTestInvoice(100, sig) },
+			sig:  sig,
+		},
+		{
+			name: "tampered body",
+			obj: func() *testInvoice {
+				inv := newTestInvoice(100, sig)
+				inv.Body.Amount = 1
+				return inv
+			},
+			sig:     sig,
+			wantErr: ErrImmutableIDContentMismatch,
+		},
+		{
+			name:    "tampered signature",
+			obj:     func() *testInvoice { return newTestInvoice(100, sig) },
+			sig:     map[string]string{"value": "forged"},
+			wantErr: ErrImmutableIDContentMismatch,
+		},
+		{
+			name: "wrong type",
+			obj: func() *testInvoice {
+				inv := newTestInvoice(100, sig)
+				inv.ID = MustNewID(idtype.User)
+				return inv
+			},
+			sig:     sig,
+			wantErr: ErrImmutableIDTypeMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyImmutableID(tt.obj(), tt.sig); err != tt.wantErr {
+				t.Errorf("VerifyImmutableID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyImmutableIDs(t *testing.T) {
+	sig := "signed"
+	tampered := newTestInvoice(3, sig)
+	tampered.Body.Amount = 4
+
+	objs := []Immutable{newTestInvoice(1, sig), newTestInvoice(2, sig), tampered}
+	sigs := []interface{}{sig, sig, sig}
+
+	t.Run("with a tampered object", func(t *testing.T) {
+		err := VerifyImmutableIDs(objs, sigs)
+
+		errs, ok := err.(ImmutableIDErrors)
+		if !ok || len(errs) != 1 {
+			t.Fatalf("VerifyImmutableIDs() error = %v, want a single failure", err)
+		}
+		if errs[0].Index != 2 || errs[0].Err != ErrImmutableIDContentMismatch {
+			t.Errorf("VerifyImmutableIDs() failure = %v, want index 2", errs[0])
+		}
+	})
+
+	t.Run("with valid objects", func(t *testing.T) {
+		if err := VerifyImmutableIDs(objs[:2], sigs[:2]); err != nil {
+			t.Errorf("VerifyImmutableIDs() error = %v", err)
+		}
+	})
+
+	t.Run("with missing signatures", func(t *testing.T) {
+		if err := VerifyImmutableIDs(objs, sigs[:1]); err == nil {
+			t.Error("VerifyImmutableIDs() expected an error")
+		}
+	})
+}
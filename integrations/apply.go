@@ -0,0 +1,432 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/gateway"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+// Errors that can occur while applying a manifest
+var (
+	ErrGatewayRequired = errors.New("a gateway client is required to provision or move resources")
+	ErrPlanNotFound    = errors.New("plan with the given label is not found for the product")
+)
+
+// ChangeAction describes what needs to happen to reconcile a manifest with
+// the current state of the API.
+type ChangeAction string
+
+const (
+	// ActionCreateProject creates the project described by the manifest
+	ActionCreateProject ChangeAction = "create-project"
+
+	// ActionProvision provisions a resource that does not exist yet
+	ActionProvision ChangeAction = "provision"
+
+	// ActionMove moves an existing resource into the manifest's project
+	ActionMove ChangeAction = "move"
+
+	// ActionDrift reports a difference that Apply will not correct, like a
+	// resource that lives in the project but is missing from the manifest.
+	ActionDrift ChangeAction = "drift"
+)
+
+// Change is a single step in a Plan.
+type Change struct {
+	Action   ChangeAction
+	Resource string
+	Detail   string
+
+	resourceID *manifold.ID
+	productID  *manifold.ID
+	planID     *manifold.ID
+	teamID     *manifold.ID
+}
+
+// Plan is the ordered list of changes required to reconcile a manifest.
+type Plan struct {
+	Project string
+	Changes []*Change
+
+	projectID *manifold.ID
+	teamID    *manifold.ID
+}
+
+// HasChanges returns whether or not applying the plan will modify anything.
+// Drift is reported but never applied, so it is not considered a change.
+func (p *Plan) HasChanges() bool {
+	for _, c := range p.Changes {
+		if c.Action != ActionDrift {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Drift returns the changes that report a difference between the manifest
+// and the API which Apply leaves untouched.
+func (p *Plan) Drift() []*Change {
+	drift := []*Change{}
+	for _, c := range p.Changes {
+		if c.Action == ActionDrift {
+			drift = append(drift, c)
+		}
+	}
+
+	return drift
+}
+
+// String returns a human readable representation of the plan.
+func (p *Plan) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "project %q:\n", p.Project)
+
+	if len(p.Changes) == 0 {
+		buf.WriteString("  no changes\n")
+		return buf.String()
+	}
+
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreateProject:
+			fmt.Fprintf(buf, "  + create project %q\n", p.Project)
+		case ActionProvision:
+			fmt.Fprintf(buf, "  + provision resource %q (%s)\n", c.Resource, c.Detail)
+		case ActionMove:
+			fmt.Fprintf(buf, "  ~ move resource %q into project %q\n", c.Resource, p.Project)
+		case ActionDrift:
+			fmt.Fprintf(buf, "  ! resource %q %s\n", c.Resource, c.Detail)
+		}
+	}
+
+	return buf.String()
+}
+
+// ApplyOpts holds the optional values for Apply.
+type ApplyOpts struct {
+	// DryRun only computes the plan, nothing is created or moved.
+	DryRun bool
+
+	// Output is where the plan is printed before it is applied. Nothing is
+	// printed when no output is given.
+	Output io.Writer
+}
+
+// Apply reconciles the API with the given manifest. It creates the project
+// if it doesn't exist, provisions missing resources and moves existing
+// resources without a project into it. Resources that live in the project but
+// are not part of the manifest, or that live in another project, are reported
+// as drift and left untouched.
+//
+// The returned Plan describes the changes, and is returned as is when a
+// DryRun is requested.
+func (c *Client) Apply(ctx context.Context, manifest *primitives.Project, opts *ApplyOpts) (*Plan, error) {
+	if opts == nil {
+		opts = &ApplyOpts{}
+	}
+
+	if !manifest.Valid() {
+		return nil, ErrProjectInvalid
+	}
+
+	plan, err := c.plan(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Output != nil {
+		if _, err := io.WriteString(opts.Output, plan.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	// Check the gateway before anything is changed, so the manifest is never
+	// partially applied. Planning doesn't need one, so dry runs don't either.
+	if c.Gateway == nil {
+		for _, change := range plan.Changes {
+			if change.Action == ActionProvision || change.Action == ActionMove {
+				return plan, ErrGatewayRequired
+			}
+		}
+	}
+
+	return plan, c.applyPlan(ctx, plan)
+}
+
+func (c *Client) plan(ctx context.Context, manifest *primitives.Project) (*Plan, error) {
+	teamID, err := c.TeamIDForLabel(ctx, manifest.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Project: manifest.Name, teamID: teamID}
+
+	pid, err := c.TeamProjectID(ctx, teamID, &manifest.Name)
+	switch err {
+	case nil:
+		plan.projectID = pid
+	case ErrProjectNotFound:
+		plan.Changes = append(plan.Changes, &Change{Action: ActionCreateProject})
+	default:
+		return nil, err
+	}
+
+	existing, err := c.teamResources(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resources are indexed per team, as a manifest can reference resources
+	// owned by other teams than the project's.
+	byLabel := map[string]map[string][]*manifold.Resource{idKey(teamID): resourcesByLabel(existing)}
+
+	declared := map[string]bool{}
+	for _, r := range manifest.Resources {
+		resTeamID := teamID
+		if r.Team != "" {
+			if resTeamID, err = c.TeamIDForLabel(ctx, r.Team); err != nil {
+				return nil, err
+			}
+		}
+
+		key := idKey(resTeamID)
+		if _, ok := byLabel[key]; !ok {
+			others, err := c.teamResources(ctx, resTeamID)
+			if err != nil {
+				return nil, err
+			}
+			byLabel[key] = resourcesByLabel(others)
+		}
+
+		sameTeam := key == idKey(teamID)
+		if sameTeam {
+			declared[r.Name] = true
+		}
+
+		candidates := byLabel[key][r.Name]
+		if len(candidates) == 0 {
+			change, err := c.provisionChange(ctx, r)
+			if err != nil {
+				return nil, err
+			}
+
+			change.teamID = resTeamID
+			plan.Changes = append(plan.Changes, change)
+			continue
+		}
+
+		// Labels are only unique per project, a resource already in the
+		// project is preferred.
+		if labelInProject(candidates, pid) != nil {
+			continue
+		}
+
+		// Resources can't be moved into a project owned by another team,
+		// they are only referenced by the manifest.
+		if !sameTeam {
+			if len(candidates) > 1 {
+				plan.Changes = append(plan.Changes, &Change{
+					Action:   ActionDrift,
+					Resource: r.Name,
+					Detail:   fmt.Sprintf("matches %d resources in other projects", len(candidates)),
+				})
+			}
+			continue
+		}
+
+		// Only resources without a project are moved into it, resources of
+		// other projects are unrelated ones sharing the label.
+		change := &Change{Action: ActionDrift, Resource: r.Name}
+		switch unattached := withoutProject(candidates); {
+		case len(unattached) == 1:
+			change.Action = ActionMove
+			change.resourceID = &unattached[0].ID
+		case len(unattached) > 1:
+			change.Detail = fmt.Sprintf("matches %d resources without a project", len(unattached))
+		case len(candidates) == 1:
+			change.Detail = "lives in another project"
+		default:
+			change.Detail = fmt.Sprintf("matches %d resources in other projects", len(candidates))
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	for _, res := range existing {
+		if pid != nil && inProject(res, pid) && !declared[res.Body.Label] {
+			plan.Changes = append(plan.Changes, &Change{
+				Action:     ActionDrift,
+				Resource:   res.Body.Label,
+				Detail:     "is not part of the manifest",
+				resourceID: &res.ID,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+func (c *Client) provisionChange(ctx context.Context, r *primitives.Resource) (*Change, error) {
+	change := &Change{
+		Action:   ActionProvision,
+		Resource: r.Name,
+		Detail:   "custom",
+	}
+
+	if r.Product == "" {
+		return change, nil
+	}
+
+	if c.Gateway == nil {
+		return nil, ErrGatewayRequired
+	}
+
+	product, err := c.Gateway.Product.Get(ctx, r.Product)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.Plans != nil {
+		for _, p := range *product.Plans {
+			if p.Label == r.Plan {
+				change.productID = &product.ID
+				change.planID = &p.ID
+				change.Detail = fmt.Sprintf("%s/%s", r.Product, r.Plan)
+				return change, nil
+			}
+		}
+	}
+
+	return nil, ErrPlanNotFound
+}
+
+func (c *Client) applyPlan(ctx context.Context, plan *Plan) error {
+	pid := plan.projectID
+
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case ActionCreateProject:
+			p, err := c.Client.Projects.Create(ctx, &manifold.CreateProject{
+				Body: manifold.CreateProjectBody{
+					TeamID: plan.teamID,
+					Name:   plan.Project,
+					Label:  plan.Project,
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			pid = &p.ID
+			c.lookups.set(projectKey(plan.teamID, plan.Project), pid, c.LookupTTL)
+		case ActionProvision:
+			if c.Gateway == nil {
+				return ErrGatewayRequired
+			}
+
+			req := &gateway.ResourceCreateRequest{
+				ProductID: change.productID,
+				PlanID:    change.planID,
+			}
+			if idKey(change.teamID) == idKey(plan.teamID) {
+				req.ProjectID = pid
+			}
+			req.Label = &change.Resource
+			req.Source = "catalog"
+			if change.productID == nil {
+				req.Source = "custom"
+			}
+			if change.teamID != nil {
+				req.Owner = &gateway.Owner{ID: *change.teamID, Type: "team"}
+			}
+
+			if _, err := c.Gateway.Resource.Create(ctx, req); err != nil {
+				return err
+			}
+			c.InvalidateResource(change.Resource)
+		case ActionMove:
+			if c.Gateway == nil {
+				return ErrGatewayRequired
+			}
+
+			_, err := c.Gateway.ID.UpdateResource(ctx, *change.resourceID, &gateway.ResourceUpdateRequest{
+				ProjectID: pid,
+			})
+			if err != nil {
+				return err
+			}
+			c.InvalidateResource(change.Resource)
+		}
+	}
+
+	return nil
+}
+
+// teamResources lists all the resources of the given team, regardless of the
+// project they belong to.
+func (c *Client) teamResources(ctx context.Context, teamID *manifold.ID) ([]*manifold.Resource, error) {
+	resourceList := c.Client.Resources.List(ctx, &manifold.ResourcesListOpts{
+		TeamID: teamID,
+	})
+	defer resourceList.Close()
+
+	resources := []*manifold.Resource{}
+	for resourceList.Next() {
+		resource, err := resourceList.Current()
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// resourcesByLabel indexes the resources by label. Labels are only unique per
+// project, so a label can match resources in multiple projects.
+func resourcesByLabel(resources []*manifold.Resource) map[string][]*manifold.Resource {
+	byLabel := make(map[string][]*manifold.Resource, len(resources))
+	for _, r := range resources {
+		byLabel[r.Body.Label] = append(byLabel[r.Body.Label], r)
+	}
+
+	return byLabel
+}
+
+// labelInProject returns the resource that is in the project, if any.
+func labelInProject(resources []*manifold.Resource, pid *manifold.ID) *manifold.Resource {
+	for _, r := range resources {
+		if inProject(r, pid) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// withoutProject returns the resources which don't belong to any project.
+func withoutProject(resources []*manifold.Resource) []*manifold.Resource {
+	out := []*manifold.Resource{}
+	for _, r := range resources {
+		if r.Body.ProjectID == nil {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
+
+func inProject(res *manifold.Resource, pid *manifold.ID) bool {
+	return pid != nil && res.Body.ProjectID != nil && *res.Body.ProjectID == *pid
+}
@@ -0,0 +1,253 @@
+package integrations_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/gateway"
+	"github.com/manifoldco/go-manifold/idtype"
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("with an invalid manifest", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		_, err := api.client(t, nil).Apply(ctx, &primitives.Project{}, nil)
+		expectErrorEqual(t, err, integrations.ErrProjectInvalid)
+	})
+
+	t.Run("with a new project", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		planID := manifold.MustNewID(idtype.Plan)
+		api.products = append(api.products, gateway.ResolvedProduct{
+			ID:    manifold.MustNewID(idtype.Product),
+			Label: "jawsdb-mysql",
+			Plans: &[]gateway.ResolvedPlan{{ID: planID, Label: "kitefin"}},
+		})
+		api.addResource("cache", nil, nil, nil)
+
+		manifest := &primitives.Project{
+			Name: "website",
+			Resources: []*primitives.Resource{
+				{Name: "db", Product: "jawsdb-mysql", Plan: "kitefin"},
+				{Name: "secrets"},
+				{Name: "cache"},
+			},
+		}
+
+		t.Run("as a dry run", func(t *testing.T) {
+			out := &bytes.Buffer{}
+			plan, err := api.client(t, nil).Apply(ctx, manifest, &integrations.ApplyOpts{
+				DryRun: true,
+				Output: out,
+			})
+			expectNoError(t, err)
+
+			expect := strings.Join([]string{
+				`project "website":`,
+				`  + create project "website"`,
+				`  + provision resource "db" (jawsdb-mysql/kitefin)`,
+				`  + provision resource "secrets" (custom)`,
+				`  ~ move resource "cache" into project "website"`,
+				``,
+			}, "\n")
+			expectStringEqual(t, out.String(), expect)
+
+			if !plan.HasChanges() {
+				t.Fatal("Expected the plan to have changes")
+			}
+
+			if n := api.requestCount("POST"); n != 0 {
+				t.Fatalf("Expected no POST requests during a dry run, got '%d'", n)
+			}
+		})
+
+		t.Run("when applied", func(t *testing.T) {
+			c := api.client(t, nil)
+			_, err := c.Apply(ctx, manifest, nil)
+			expectNoError(t, err)
+
+			plan, err := c.Apply(ctx, manifest, &integrations.ApplyOpts{DryRun: true})
+			expectNoError(t, err)
+			if plan.HasChanges() {
+				t.Fatalf("Expected no changes after applying, got:\n%s", plan)
+			}
+
+			res, err := c.GetResource(ctx, &manifest.Name, &primitives.Resource{Name: "db"})
+			expectNoError(t, err)
+			if res.Body.PlanID == nil || *res.Body.PlanID != planID {
+				t.Fatal("Expected the resource to be provisioned with the requested plan")
+			}
+		})
+	})
+
+	t.Run("with drift", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		pid := api.addProject("website", nil)
+		api.addResource("db", nil, &pid, nil)
+		api.addResource("legacy", nil, &pid, nil)
+
+		plan, err := api.client(t, nil).Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}},
+		}, nil)
+		expectNoError(t, err)
+
+		drift := plan.Drift()
+		if len(drift) != 1 || drift[0].Resource != "legacy" {
+			t.Fatalf("Expected 'legacy' to be reported as drift, got:\n%s", plan)
+		}
+
+		if plan.HasChanges() {
+			t.Fatal("Expected drift not to be considered a change")
+		}
+	})
+
+	t.Run("with an unknown plan", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		api.products = append(api.products, gateway.ResolvedProduct{
+			ID:    manifold.MustNewID(idtype.Product),
+			Label: "jawsdb-mysql",
+		})
+
+		_, err := api.client(t, nil).Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db", Product: "jawsdb-mysql", Plan: "unknown"}},
+		}, &integrations.ApplyOpts{DryRun: true})
+		expectErrorEqual(t, err, integrations.ErrPlanNotFound)
+	})
+
+	t.Run("without a gateway", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		c := api.client(t, nil)
+		c.Gateway = nil
+
+		_, err := c.Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}},
+		}, nil)
+		expectErrorEqual(t, err, integrations.ErrGatewayRequired)
+
+		if n := api.requestCount("POST "); n != 0 {
+			t.Errorf("Expected nothing to be created without a gateway, got '%d' POST requests", n)
+		}
+	})
+
+	t.Run("as a dry run without a gateway", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		api.addProject("website", nil)
+		api.addResource("cache", nil, nil, nil)
+
+		c := api.client(t, nil)
+		c.Gateway = nil
+
+		plan, err := c.Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}, {Name: "cache"}},
+		}, &integrations.ApplyOpts{DryRun: true})
+		expectNoError(t, err)
+
+		if len(plan.Changes) != 2 {
+			t.Errorf("Expected a provision and a move, got:\n%s", plan)
+		}
+	})
+
+	t.Run("with a label in multiple projects", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		pid := api.addProject("website", nil)
+		other := api.addProject("other", nil)
+		api.addResource("db", nil, &other, nil)
+		api.addResource("db", nil, &pid, nil)
+		api.addResource("db", nil, &other, nil)
+
+		plan, err := api.client(t, nil).Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}},
+		}, &integrations.ApplyOpts{DryRun: true})
+		expectNoError(t, err)
+
+		if plan.HasChanges() {
+			t.Errorf("Expected the resource in the project to be used, got:\n%s", plan)
+		}
+	})
+
+	t.Run("with a label in another project", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		api.addProject("website", nil)
+		other := api.addProject("other", nil)
+		api.addResource("db", nil, &other, nil)
+
+		plan, err := api.client(t, nil).Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}},
+		}, &integrations.ApplyOpts{DryRun: true})
+		expectNoError(t, err)
+
+		drift := plan.Drift()
+		if plan.HasChanges() || len(drift) != 1 || drift[0].Detail != "lives in another project" {
+			t.Errorf("Expected 'db' to be left in its project, got:\n%s", plan)
+		}
+	})
+
+	t.Run("with a label in another project and without a project", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		api.addProject("website", nil)
+		other := api.addProject("other", nil)
+		api.addResource("db", nil, &other, nil)
+		api.addResource("db", nil, nil, nil)
+
+		plan, err := api.client(t, nil).Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}},
+		}, &integrations.ApplyOpts{DryRun: true})
+		expectNoError(t, err)
+
+		if len(plan.Changes) != 1 || plan.Changes[0].Action != integrations.ActionMove {
+			t.Errorf("Expected the resource without a project to be moved, got:\n%s", plan)
+		}
+	})
+
+	t.Run("with an ambiguous label", func(t *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		api.addProject("website", nil)
+		api.addResource("db", nil, nil, nil)
+		api.addResource("db", nil, nil, nil)
+
+		plan, err := api.client(t, nil).Apply(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}},
+		}, &integrations.ApplyOpts{DryRun: true})
+		expectNoError(t, err)
+
+		drift := plan.Drift()
+		if plan.HasChanges() || len(drift) != 1 || drift[0].Resource != "db" {
+			t.Errorf("Expected 'db' to be reported as ambiguous, got:\n%s", plan)
+		}
+	})
+}
@@ -0,0 +1,329 @@
+package integrations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/nacl/secretbox"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/integrations/internal/atomicfile"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+const (
+	cacheKeySize  = 32
+	cacheSaltFile = "salt"
+	cacheSaltSize = 16
+	nonceSize     = 24
+)
+
+// Errors related to the credential cache
+var (
+	ErrCacheKeySize   = errors.New("the cache key must be 32 bytes long")
+	ErrCacheCorrupted = errors.New("the cache entry could not be decrypted")
+)
+
+// CacheResult describes the outcome of a cache lookup.
+type CacheResult string
+
+const (
+	// CacheHit means the credentials were served from the cache
+	CacheHit CacheResult = "hit"
+
+	// CacheMiss means no cached credentials were available
+	CacheMiss CacheResult = "miss"
+
+	// CacheStale means cached credentials were found, but they are older
+	// than the allowed staleness and were not used
+	CacheStale CacheResult = "stale"
+)
+
+// CacheEvent is reported every time the client falls back to the cache
+// because the API is unreachable.
+type CacheEvent struct {
+	Result CacheResult
+	Age    time.Duration // Age of the cached entry, if one was found
+	Err    error         // Error returned by the API
+}
+
+// CacheStats holds the counters of a CredentialCache.
+type CacheStats struct {
+	Hits   int
+	Misses int
+	Writes int
+}
+
+// CredentialCache stores resolved CredentialValues on disk, encrypted at rest,
+// so the client can keep serving them while the API is unreachable.
+//
+// Every successful lookup refreshes the cache. The cache is only read when
+// the API can't be reached or returns a server error.
+type CredentialCache struct {
+	// MaxStaleness is the maximum age of an entry that is still served. A
+	// zero value serves entries regardless of their age.
+	MaxStaleness time.Duration
+
+	// Notify, when set, is called every time the cache is used as a
+	// fallback, allowing operators to know they are running on cached
+	// credentials.
+	Notify func(*CacheEvent)
+
+	dir string
+	key [cacheKeySize]byte
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// NewCredentialCache returns a CredentialCache storing its entries in dir,
+// encrypted with the given 32 byte key.
+func NewCredentialCache(dir string, key []byte) (*CredentialCache, error) {
+	if len(key) != cacheKeySize {
+		return nil, ErrCacheKeySize
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	cc := &CredentialCache{dir: dir}
+	copy(cc.key[:], key)
+	return cc, nil
+}
+
+// NewCredentialCacheFromPassphrase returns a CredentialCache storing its
+// entries in dir, encrypted with a key derived from the passphrase. The key
+// is derived with the same scrypt parameters used for logging in, using a
+// random salt that is stored alongside the entries.
+func NewCredentialCacheFromPassphrase(dir, passphrase string) (*CredentialCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	salt, err := cacheSalt(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := manifold.DeriveKey(passphrase, salt, cacheKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCredentialCache(dir, key)
+}
+
+// Stats returns the hit, miss and write counters of the cache.
+func (cc *CredentialCache) Stats() CacheStats {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	return cc.stats
+}
+
+type cacheEntry struct {
+	CachedAt    time.Time                                `json:"cached_at"`
+	Credentials map[string][]*primitives.CredentialValue `json:"credentials"`
+}
+
+func (cc *CredentialCache) store(key string, creds map[string][]*primitives.CredentialValue) error {
+	b, err := json.Marshal(&cacheEntry{CachedAt: time.Now().UTC(), Credentials: creds})
+	if err != nil {
+		return err
+	}
+
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return err
+	}
+
+	sealed := secretbox.Seal(nonce[:], b, &nonce, &cc.key)
+	if err := atomicfile.WriteFile(cc.path(key), sealed, 0600); err != nil {
+		return err
+	}
+
+	cc.mu.Lock()
+	cc.stats.Writes++
+	cc.mu.Unlock()
+
+	return nil
+}
+
+// fallback returns the cached credentials for the key, if they are within
+// the allowed staleness. The API error that triggered the fallback is
+// reported alongside the result.
+func (cc *CredentialCache) fallback(key string, apiErr error) (map[string][]*primitives.CredentialValue, bool) {
+	evt := &CacheEvent{Result: CacheMiss, Err: apiErr}
+	entry, err := cc.load(key)
+	if err == nil {
+		evt.Age = time.Since(entry.CachedAt)
+		evt.Result = CacheHit
+		if cc.MaxStaleness > 0 && evt.Age > cc.MaxStaleness {
+			evt.Result = CacheStale
+		}
+	}
+
+	cc.mu.Lock()
+	if evt.Result == CacheHit {
+		cc.stats.Hits++
+	} else {
+		cc.stats.Misses++
+	}
+	cc.mu.Unlock()
+
+	if cc.Notify != nil {
+		cc.Notify(evt)
+	}
+
+	if evt.Result != CacheHit {
+		return nil, false
+	}
+
+	return entry.Credentials, true
+}
+
+func (cc *CredentialCache) load(key string) (*cacheEntry, error) {
+	sealed, err := ioutil.ReadFile(cc.path(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < nonceSize {
+		return nil, ErrCacheCorrupted
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], sealed[:nonceSize])
+
+	b, ok := secretbox.Open(nil, sealed[nonceSize:], &nonce, &cc.key)
+	if !ok {
+		return nil, ErrCacheCorrupted
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (cc *CredentialCache) path(key string) string {
+	return filepath.Join(cc.dir, key)
+}
+
+// NewCachedClient returns a new wrapper client for the provided client, bound
+// to the provided team, which serves credentials from the cache when the API
+// is unreachable. Unlike NewClient, it doesn't fail when the team can't be
+// looked up because the API is unreachable; the lookup is retried on the
+// next request instead.
+func NewCachedClient(cl *manifold.Client, team *string, cache *CredentialCache) (*Client, error) {
+	c, err := NewClient(cl, team)
+	if err != nil && !unreachable(err) {
+		return nil, err
+	}
+
+	c.Cache = cache
+	return c, nil
+}
+
+// cachedCredentials fetches credentials, storing them in the cache on success
+// and falling back to the cache when the API is unreachable.
+func (c *Client) cachedCredentials(ctx context.Context, parts []interface{}, fetch func() (map[string][]*primitives.CredentialValue, error)) (map[string][]*primitives.CredentialValue, error) {
+	if c.Cache == nil {
+		return fetch()
+	}
+
+	key, err := cacheKey(append([]interface{}{c.team}, parts...))
+	if err != nil {
+		return nil, err
+	}
+
+	// NewCachedClient skips the team lookup when the API is down, so it has to
+	// be retried before fetching.
+	err = c.ensureTeamID(ctx)
+
+	var creds map[string][]*primitives.CredentialValue
+	if err == nil {
+		creds, err = fetch()
+	}
+
+	if err == nil {
+		// A failure to write the cache shouldn't fail the lookup itself.
+		c.Cache.store(key, creds)
+		return creds, nil
+	}
+
+	if !unreachable(err) {
+		return nil, err
+	}
+
+	if cached, ok := c.Cache.fallback(key, err); ok {
+		return cached, nil
+	}
+
+	return nil, err
+}
+
+// cacheKey derives a file name from the parameters of a lookup.
+func cacheKey(parts []interface{}) (string, error) {
+	b, err := json.Marshal(parts)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// unreachable returns whether the error means the API could not be reached
+// or failed to handle the request, as opposed to rejecting it. Requests
+// cancelled by the caller are not considered unreachable.
+func unreachable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	switch e := err.(type) {
+	case *url.Error, net.Error:
+		return true
+	case *manifold.Error:
+		return e.StatusCode() >= 500
+	case *TeamError:
+		return unreachable(e.Err)
+	default:
+		return false
+	}
+}
+
+func cacheSalt(dir string) ([]byte, error) {
+	path := filepath.Join(dir, cacheSaltFile)
+
+	salt, err := ioutil.ReadFile(path)
+	if err == nil && len(salt) == cacheSaltSize {
+		return salt, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	salt = make([]byte, cacheSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	return salt, atomicfile.WriteFile(path, salt, 0600)
+}
@@ -0,0 +1,225 @@
+package integrations_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+func TestCredentialCache(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "manifold-cache")
+	expectNoError(t, err)
+	defer os.RemoveAll(dir)
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	pid := api.addProject("website", nil)
+	api.addResource("secrets", nil, &pid, map[string]string{"TOKEN": "abc"})
+	project := &primitives.Project{Name: "website"}
+
+	newCache := func(t *testing.T, passphrase string) *integrations.CredentialCache {
+		cc, err := integrations.NewCredentialCacheFromPassphrase(dir, passphrase)
+		expectNoError(t, err)
+		return cc
+	}
+
+	cache := newCache(t, "correct horse")
+	events := []*integrations.CacheEvent{}
+	cache.Notify = func(evt *integrations.CacheEvent) {
+		events = append(events, evt)
+	}
+
+	cl := api.client(t, nil)
+	cl.Cache = cache
+
+	t.Run("with a reachable API", func(t *testing.T) {
+		creds, err := cl.GetProjectCredentialValues(ctx, project)
+		expectNoError(t, err)
+		expectStringEqual(t, creds["secrets"][0].Value, "abc")
+
+		if s := cache.Stats(); s.Writes != 1 || s.Hits != 0 {
+			t.Errorf("Expected a single write, got %+v", s)
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		expectNoError(t, err)
+		for _, f := range files {
+			fi, err := os.Stat(f)
+			expectNoError(t, err)
+			if fi.Mode().Perm() != 0600 {
+				t.Errorf("Expected '%s' to have mode 0600, got '%s'", f, fi.Mode().Perm())
+			}
+
+			b, err := ioutil.ReadFile(f)
+			expectNoError(t, err)
+			if strings.Contains(string(b), "abc") {
+				t.Errorf("Expected '%s' to be encrypted", f)
+			}
+		}
+	})
+
+	t.Run("with an unknown resource", func(t *testing.T) {
+		_, err := cl.GetResourcesCredentialValues(ctx, &project.Name, []*primitives.Resource{{Name: "missing"}})
+		expectErrorEqual(t, err, integrations.ErrResourceNotFound)
+	})
+
+	api.setFailing(true)
+	defer api.setFailing(false)
+
+	t.Run("with an unavailable API", func(t *testing.T) {
+		creds, err := cl.GetProjectCredentialValues(ctx, project)
+		expectNoError(t, err)
+		expectStringEqual(t, creds["secrets"][0].Value, "abc")
+
+		if len(events) != 1 || events[0].Result != integrations.CacheHit || events[0].Err == nil {
+			t.Fatalf("Expected a single hit event, got %+v", events)
+		}
+	})
+
+	t.Run("with a cancelled context", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if _, err := cl.GetProjectCredentialValues(cctx, project); err == nil {
+			t.Fatal("Expected the cancellation not to be served from the cache")
+		}
+	})
+
+	t.Run("with an uncached lookup", func(t *testing.T) {
+		_, err := cl.GetResourcesCredentialValues(ctx, &project.Name, nil)
+		if err == nil {
+			t.Fatal("Expected the API error to be returned")
+		}
+
+		if events[len(events)-1].Result != integrations.CacheMiss {
+			t.Errorf("Expected a miss event, got %+v", events[len(events)-1])
+		}
+	})
+
+	t.Run("with a stale entry", func(t *testing.T) {
+		cache.MaxStaleness = time.Nanosecond
+		defer func() { cache.MaxStaleness = 0 }()
+
+		_, err := cl.GetProjectCredentialValues(ctx, project)
+		if err == nil {
+			t.Fatal("Expected the API error to be returned")
+		}
+
+		if events[len(events)-1].Result != integrations.CacheStale {
+			t.Errorf("Expected a stale event, got %+v", events[len(events)-1])
+		}
+	})
+
+	t.Run("when reopening the cache", func(t *testing.T) {
+		c := api.client(t, nil)
+		c.Cache = newCache(t, "correct horse")
+
+		creds, err := c.GetProjectCredentialValues(ctx, project)
+		expectNoError(t, err)
+		expectStringEqual(t, creds["secrets"][0].Value, "abc")
+	})
+
+	t.Run("with the wrong passphrase", func(t *testing.T) {
+		c := api.client(t, nil)
+		c.Cache = newCache(t, "wrong")
+
+		if _, err := c.GetProjectCredentialValues(ctx, project); err == nil {
+			t.Fatal("Expected the API error to be returned")
+		}
+	})
+
+	t.Run("with an invalid key size", func(t *testing.T) {
+		_, err := integrations.NewCredentialCache(dir, []byte("short"))
+		expectErrorEqual(t, err, integrations.ErrCacheKeySize)
+	})
+}
+
+func TestCachedClient_Concurrent(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "manifold-cache")
+	expectNoError(t, err)
+	defer os.RemoveAll(dir)
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	tid := api.addTeam("platform")
+	pid := api.addProject("website", &tid)
+	api.addResource("secrets", &tid, &pid, map[string]string{"TOKEN": "abc"})
+
+	cache, err := integrations.NewCredentialCacheFromPassphrase(dir, "correct horse")
+	expectNoError(t, err)
+
+	// The team is looked up lazily, as the API is down when the client is
+	// created.
+	api.setFailing(true)
+	cl, err := integrations.NewCachedClient(api.manifoldClient(), strPtr("platform"), cache)
+	expectNoError(t, err)
+	api.setFailing(false)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			creds, err := cl.GetProjectCredentialValues(ctx, &primitives.Project{Name: "website"})
+			expectNoError(t, err)
+			expectStringEqual(t, creds["secrets"][0].Value, "abc")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCachedClient_LazyTeam(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "manifold-cache")
+	expectNoError(t, err)
+	defer os.RemoveAll(dir)
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	tid := api.addTeam("platform")
+	api.addResource("secrets", nil, nil, map[string]string{"TOKEN": "personal"})
+	rid := api.addResource("secrets", &tid, nil, map[string]string{"TOKEN": "team"})
+
+	cache, err := integrations.NewCredentialCacheFromPassphrase(dir, "correct horse")
+	expectNoError(t, err)
+
+	api.setFailing(true)
+	cl, err := integrations.NewCachedClient(api.manifoldClient(), strPtr("platform"), cache)
+	expectNoError(t, err)
+
+	t.Run("while the API is down", func(t *testing.T) {
+		_, err := cl.GetResources(ctx, nil, []*primitives.Resource{{Name: "secrets"}})
+		if err == nil {
+			t.Fatal("Expected an error while the team can't be looked up")
+		}
+	})
+
+	api.setFailing(false)
+
+	t.Run("once the API is back", func(t *testing.T) {
+		resources, err := cl.GetResources(ctx, nil, []*primitives.Resource{{Name: "secrets"}})
+		expectNoError(t, err)
+
+		if len(resources) != 1 || resources[0].ID != rid {
+			t.Fatalf("Expected the team's resource %s, got %v", rid, resources)
+		}
+		expectStringEqual(t, cl.TeamID.String(), tid.String())
+	})
+}
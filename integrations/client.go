@@ -3,9 +3,13 @@ package integrations
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/gateway"
 	"github.com/manifoldco/go-manifold/integrations/primitives"
 )
 
@@ -24,25 +28,70 @@ var (
 	ErrCredentialDefaultNotSet = errors.New("you did not provide a default for a the non-existing credential")
 )
 
+// TeamError is returned when a team lookup fails, naming the team it failed
+// for.
+//
+// NewClient used to return ErrTeamNotFound as is, it now returns a TeamError
+// wrapping it. Use errors.Is(err, ErrTeamNotFound) instead of comparing the
+// errors.
+type TeamError struct {
+	Team string
+	Err  error
+}
+
+func (e *TeamError) Error() string {
+	return fmt.Sprintf("team %q: %s", e.Team, e.Err)
+}
+
+// Unwrap returns the error the team lookup failed with.
+func (e *TeamError) Unwrap() error {
+	return e.Err
+}
+
 // Client is a wrapper around the manifold client.
 type Client struct {
 	*manifold.Client
 	TeamID *manifold.ID
 
+	// Gateway is used to provision and move resources. It is only required
+	// when applying a manifest.
+	Gateway *gateway.Client
+
+	// Cache, when set, stores the credentials fetched by
+	// GetProjectCredentialValues and GetResourcesCredentialValues so they can
+	// still be served while the API is unreachable.
+	Cache *CredentialCache
+
+	// LookupTTL is how long the teams, projects and resources looked up by
+	// label are cached. With a zero value, resources aren't cached at all,
+	// while team and project IDs are cached until they are invalidated.
+	LookupTTL time.Duration
+
+	// RWMutex isn't used by the client anymore, it is only kept for
+	// compatibility.
 	sync.RWMutex
-	team       *string
-	projectIDs map[string]*manifold.ID
+
+	// teamMu guards TeamID, which NewCachedClient looks up lazily.
+	teamMu  sync.Mutex
+	team    *string
+	lookups *lookupCache
 }
 
 // NewClient returns a new wrapper client for the provided client, bound to the
 // provided team.
 func NewClient(cl *manifold.Client, team *string) (*Client, error) {
+	return NewClientContext(context.Background(), cl, team)
+}
+
+// NewClientContext is like NewClient, using the given context to look up the
+// team.
+func NewClientContext(ctx context.Context, cl *manifold.Client, team *string) (*Client, error) {
 	c := &Client{
-		Client:     cl,
-		team:       team,
-		projectIDs: map[string]*manifold.ID{},
+		Client:  cl,
+		team:    team,
+		lookups: newLookupCache(),
 	}
-	return c, c.ensureTeamID()
+	return c, c.ensureTeamID(ctx)
 }
 
 // GetResource gets a resource for a specific label. If no resource is given,
@@ -70,6 +119,12 @@ func (c *Client) GetResource(ctx context.Context, project *string, res *primitiv
 // GetResourcesCredentialValues, only the Resources that are a member of
 // the Project are loaded.
 func (c *Client) GetProjectCredentialValues(ctx context.Context, project *primitives.Project) (map[string][]*primitives.CredentialValue, error) {
+	return c.cachedCredentials(ctx, []interface{}{"project", project}, func() (map[string][]*primitives.CredentialValue, error) {
+		return c.getProjectCredentialValues(ctx, project)
+	})
+}
+
+func (c *Client) getProjectCredentialValues(ctx context.Context, project *primitives.Project) (map[string][]*primitives.CredentialValue, error) {
 	if !project.Valid() {
 		return nil, ErrProjectInvalid
 	}
@@ -80,23 +135,36 @@ func (c *Client) GetProjectCredentialValues(ctx context.Context, project *primit
 		}
 	}
 
-	resources, err := c.GetResources(ctx, &project.Name, project.Resources)
+	resources, err := c.getResources(ctx, project.Team, &project.Name, project.Resources)
 	if err != nil {
 		return nil, err
 	}
 
-	resourceNames := map[manifold.ID]string{}
-	for _, res := range resources {
-		resourceNames[res.ID] = res.Body.Label
+	teamID, err := c.TeamIDForLabel(ctx, project.Team)
+	if err != nil {
+		return nil, err
 	}
 
-	pid, err := c.ProjectID(&project.Name)
+	pid, err := c.TeamProjectID(ctx, teamID, &project.Name)
 	if err != nil {
 		return nil, err
 	}
-	return c.credentialsByParam(ctx, &project.Name, project.Resources, resourceNames, &manifold.CredentialsListOpts{
-		ProjectID: pid,
-	})
+
+	resourceIDs := make([]manifold.ID, len(resources))
+	resourceNames := map[manifold.ID]string{}
+	params := &manifold.CredentialsListOpts{ProjectID: pid}
+	for i, res := range resources {
+		resourceIDs[i] = res.ID
+		resourceNames[res.ID] = res.Body.Label
+
+		// Resources owned by another team are not part of the project, so
+		// their credentials have to be listed by resource instead.
+		if !inProject(res, pid) {
+			params = &manifold.CredentialsListOpts{ResourceID: &resourceIDs}
+		}
+	}
+
+	return c.credentialsByParam(ctx, &project.Name, project.Resources, resourceNames, params)
 }
 
 // GetResourceCredentialValues is a wrapper function that knows how to get a set
@@ -123,6 +191,12 @@ func (c *Client) GetResourceCredentialValues(ctx context.Context, project *strin
 // value, it will be added to the list. If no default value is given, it will
 // error.
 func (c *Client) GetResourcesCredentialValues(ctx context.Context, project *string, res []*primitives.Resource) (map[string][]*primitives.CredentialValue, error) {
+	return c.cachedCredentials(ctx, []interface{}{"resources", project, res}, func() (map[string][]*primitives.CredentialValue, error) {
+		return c.getResourcesCredentialValues(ctx, project, res)
+	})
+}
+
+func (c *Client) getResourcesCredentialValues(ctx context.Context, project *string, res []*primitives.Resource) (map[string][]*primitives.CredentialValue, error) {
 	for _, r := range res {
 		if !r.Valid() {
 			return nil, ErrResourceInvalid
@@ -267,34 +341,68 @@ func setCredentialValueFields(cv *primitives.CredentialValue, label string, res
 // GetResources fetches a set of resources according to their labels. If no
 // resources are given, all the resources will be fetched. If one of the
 // requested resources is not available, this will return an error.
+//
+// Resources declaring a Team other than the client's are looked up in that
+// team, outside of the project.
 func (c *Client) GetResources(ctx context.Context, project *string, res []*primitives.Resource) ([]*manifold.Resource, error) {
+	return c.getResources(ctx, "", project, res)
+}
+
+// getResources fetches resources for a project owned by the given team. An
+// empty team is the team the client is bound to.
+func (c *Client) getResources(ctx context.Context, team string, project *string, res []*primitives.Resource) ([]*manifold.Resource, error) {
 	for _, r := range res {
 		if !r.Valid() {
 			return nil, ErrResourceInvalid
 		}
 	}
 
-	pID, err := c.ProjectID(project)
+	projectTeamID, err := c.TeamIDForLabel(ctx, team)
 	if err != nil {
 		return nil, err
 	}
 
-	resourceList := c.Client.Resources.List(ctx, &manifold.ResourcesListOpts{
-		ProjectID: pID,
-		TeamID:    c.TeamID,
-	})
-	defer resourceList.Close()
+	// Group the requested resources by the ID of their team, so every team is
+	// only queried once.
+	teamIDs := map[string]*manifold.ID{idKey(projectTeamID): projectTeamID}
+	byTeam := map[string][]*primitives.Resource{}
+	teams := []string{}
+	if len(res) == 0 {
+		teams = append(teams, idKey(projectTeamID))
+	}
+
+	for _, r := range res {
+		teamID := projectTeamID
+		if r.Team != "" {
+			teamID, err = c.TeamIDForLabel(ctx, r.Team)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		key := idKey(teamID)
+		if _, ok := byTeam[key]; !ok {
+			teams = append(teams, key)
+		}
+		teamIDs[key] = teamID
+		byTeam[key] = append(byTeam[key], r)
+	}
 
 	resources := []*manifold.Resource{}
-	for resourceList.Next() {
-		resource, err := resourceList.Current()
-		if err != nil {
-			return nil, err
+	for _, key := range teams {
+		opts := &manifold.ResourcesListOpts{TeamID: teamIDs[key]}
+		if key == idKey(projectTeamID) {
+			opts.ProjectID, err = c.TeamProjectID(ctx, projectTeamID, project)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		if requestedResource(resource, res) {
-			resources = append(resources, resource)
+		found, err := c.listResources(ctx, opts, byTeam[key])
+		if err != nil {
+			return nil, err
 		}
+		resources = append(resources, found...)
 	}
 
 	if len(resources) != len(res) && len(res) != 0 {
@@ -304,20 +412,119 @@ func (c *Client) GetResources(ctx context.Context, project *string, res []*primi
 	return resources, nil
 }
 
+// listResources lists the resources matching the options. When specific
+// resources are requested, they are looked up by label and cached. A single
+// resource is listed by its label, while multiple resources are listed with a
+// single request and matched by label locally.
+func (c *Client) listResources(ctx context.Context, opts *manifold.ResourcesListOpts, res []*primitives.Resource) ([]*manifold.Resource, error) {
+	if len(res) == 0 {
+		return c.listAllResources(ctx, opts)
+	}
+
+	byLabel := map[string][]*manifold.Resource{}
+	missing := []string{}
+	lookedUp := map[string]bool{}
+	for _, r := range res {
+		if _, ok := byLabel[r.Name]; ok {
+			continue
+		}
+
+		if v, ok := c.lookups.get(resourceKey(opts.TeamID, opts.ProjectID, r.Name)); ok && c.LookupTTL > 0 {
+			byLabel[r.Name] = v.([]*manifold.Resource)
+			continue
+		}
+
+		byLabel[r.Name] = []*manifold.Resource{}
+		missing = append(missing, r.Name)
+		lookedUp[r.Name] = true
+	}
+
+	if len(missing) > 0 {
+		listOpts := &manifold.ResourcesListOpts{
+			TeamID:    opts.TeamID,
+			ProjectID: opts.ProjectID,
+		}
+		if len(missing) == 1 {
+			listOpts.Label = &missing[0]
+		}
+
+		found, err := c.listAllResources(ctx, listOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range found {
+			if label := resource.Body.Label; lookedUp[label] {
+				byLabel[label] = append(byLabel[label], resource)
+			}
+		}
+
+		// Missing resources aren't cached, they could be created any time.
+		// Resources are only cached with a TTL, as they can be deleted and
+		// provisioned again under the same label.
+		for _, label := range missing {
+			if len(byLabel[label]) > 0 && c.LookupTTL > 0 {
+				c.lookups.set(resourceKey(opts.TeamID, opts.ProjectID, label), byLabel[label], c.LookupTTL)
+			}
+		}
+	}
+
+	resources := []*manifold.Resource{}
+	for _, r := range res {
+		resources = append(resources, byLabel[r.Name]...)
+	}
+
+	return resources, nil
+}
+
+func (c *Client) listAllResources(ctx context.Context, opts *manifold.ResourcesListOpts) ([]*manifold.Resource, error) {
+	resourceList := c.Client.Resources.List(ctx, opts)
+	defer resourceList.Close()
+
+	resources := []*manifold.Resource{}
+	for resourceList.Next() {
+		resource, err := resourceList.Current()
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
 // ProjectID will return the ID for a project based on it's label. It uses an
 // internal cache so it doesn't have to multiple requests for a single label.
 func (c *Client) ProjectID(label *string) (*manifold.ID, error) {
+	return c.ProjectIDContext(context.Background(), label)
+}
+
+// ProjectIDContext is like ProjectID, using the given context for the lookup.
+func (c *Client) ProjectIDContext(ctx context.Context, label *string) (*manifold.ID, error) {
+	teamID, err := c.teamID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.TeamProjectID(ctx, teamID, label)
+}
+
+// TeamProjectID returns the ID for a project owned by the given team, based
+// on its label. Like ProjectID, the IDs are cached per team.
+func (c *Client) TeamProjectID(ctx context.Context, teamID *manifold.ID, label *string) (*manifold.ID, error) {
 	if label == nil {
 		return nil, nil
 	}
 
-	if v, ok := c.getCachedProjectID(*label); ok {
-		return v, nil
+	key := projectKey(teamID, *label)
+	if v, ok := c.lookups.get(key); ok {
+		return v.(*manifold.ID), nil
 	}
 
-	projectList := c.Client.Projects.List(context.Background(), &manifold.ProjectsListOpts{
+	projectList := c.Client.Projects.List(ctx, &manifold.ProjectsListOpts{
 		Label:  label,
-		TeamID: c.TeamID,
+		TeamID: teamID,
 	})
 	defer projectList.Close()
 
@@ -328,7 +535,7 @@ func (c *Client) ProjectID(label *string) (*manifold.ID, error) {
 		}
 
 		if project.Body.Label == *label {
-			c.setCachedProjectID(*label, &project.ID)
+			c.lookups.set(key, &project.ID, c.LookupTTL)
 			return &project.ID, nil
 		}
 	}
@@ -336,59 +543,103 @@ func (c *Client) ProjectID(label *string) (*manifold.ID, error) {
 	return nil, ErrProjectNotFound
 }
 
-// wrapper around the map to get a ReadLock for concurrency. This could easily
-// be done with Go 1.9 sync.Map, but we want to support versions below.
-func (c *Client) getCachedProjectID(label string) (*manifold.ID, bool) {
-	c.RLock()
-	defer c.RUnlock()
+// TeamIDForLabel returns the ID of the team with the given label. An empty
+// label returns the ID of the team the client is bound to, which is nil when
+// it is bound to the user. Team IDs are cached, so the teams are only listed
+// again when a label is not known yet.
+func (c *Client) TeamIDForLabel(ctx context.Context, label string) (*manifold.ID, error) {
+	if label == "" {
+		return c.teamID(ctx)
+	}
+
+	if v, ok := c.lookups.get(teamLabelKey(label)); ok {
+		return v.(*manifold.ID), nil
+	}
+
+	// The teams endpoint doesn't support filtering by label, so every team
+	// is cached while we're at it.
+	teamsList := c.Client.Teams.List(ctx)
+	defer teamsList.Close()
+
+	var id *manifold.ID
+	for teamsList.Next() {
+		team, err := teamsList.Current()
+		if err != nil {
+			return nil, &TeamError{Team: label, Err: err}
+		}
+
+		teamID := team.ID
+		c.lookups.set(teamLabelKey(team.Body.Label), &teamID, c.LookupTTL)
+		if team.Body.Label == label {
+			id = &teamID
+		}
+	}
+
+	if id == nil {
+		return nil, &TeamError{Team: label, Err: ErrTeamNotFound}
+	}
 
-	v, ok := c.projectIDs[label]
-	return v, ok
+	return id, nil
 }
 
-// wrapper around the map to get a Lock for concurrency. This could easily be
-// done with Go 1.9 sync.Map, but we want to support versions below.
-func (c *Client) setCachedProjectID(label string, id *manifold.ID) {
-	c.Lock()
-	defer c.Unlock()
+// InvalidateLookups drops every cached team, project and resource lookup.
+func (c *Client) InvalidateLookups() {
+	c.lookups.invalidate(func(string) bool { return true })
+}
 
-	c.projectIDs[label] = id
+// InvalidateResource drops the cached lookups of the resource with the given
+// label, in every team and project. It should be called after a resource is
+// moved or deleted outside of the client.
+func (c *Client) InvalidateResource(label string) {
+	c.lookups.invalidate(func(key string) bool {
+		return strings.HasPrefix(key, "resource/") && strings.HasSuffix(key, "/"+label)
+	})
 }
 
-func (c *Client) ensureTeamID() error {
+// ensureTeamID looks up the ID of the team the client is bound to, unless it
+// is already known. Concurrent callers wait for a single lookup.
+func (c *Client) ensureTeamID(ctx context.Context) error {
 	if c.team == nil || *c.team == "" {
 		// no team specified, skip it
 		return nil
 	}
 
-	teamsList := c.Client.Teams.List(context.Background())
-	defer teamsList.Close()
+	c.teamMu.Lock()
+	defer c.teamMu.Unlock()
 
-	for teamsList.Next() {
-		team, err := teamsList.Current()
-		if err != nil {
-			return err
-		}
+	if c.TeamID != nil {
+		return nil
+	}
 
-		if team.Body.Label == *c.team {
-			c.TeamID = &team.ID
-			return nil
-		}
+	id, err := c.TeamIDForLabel(ctx, *c.team)
+	if err != nil {
+		return err
 	}
 
-	return ErrTeamNotFound
+	c.TeamID = id
+	return nil
 }
 
-func requestedResource(res *manifold.Resource, ress []*primitives.Resource) bool {
-	if len(ress) == 0 {
-		return true
+// teamID returns the ID of the team the client is bound to. The team is looked
+// up first if it couldn't be when the client was created, so requests never
+// fall back to the user's own resources.
+func (c *Client) teamID(ctx context.Context) (*manifold.ID, error) {
+	if err := c.ensureTeamID(ctx); err != nil {
+		return nil, err
 	}
 
-	for _, r := range ress {
-		if res.Body.Label == r.Name {
-			return true
-		}
+	c.teamMu.Lock()
+	defer c.teamMu.Unlock()
+
+	return c.TeamID, nil
+}
+
+// idKey is used to index caches by an optional ID, like a team ID which is
+// nil for the user's own resources.
+func idKey(id *manifold.ID) string {
+	if id == nil {
+		return ""
 	}
 
-	return false
+	return id.String()
 }
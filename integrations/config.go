@@ -0,0 +1,324 @@
+package integrations
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+// Errors that can occur while updating the config of a custom resource
+var (
+	ErrResourceNotCustom  = errors.New("only custom resources have a config that can be updated")
+	ErrInvalidConfigKey   = errors.New("must be an uppercase credential key, like DATABASE_URL")
+	ErrInvalidConfigValue = errors.New("the value is too long")
+)
+
+// ConfigError is returned when a key or value of a config update is invalid.
+type ConfigError struct {
+	Key string
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Err)
+}
+
+// ConfigChangeType describes what happens to a key of a config.
+type ConfigChangeType string
+
+const (
+	// ConfigAdded is a key that doesn't exist yet
+	ConfigAdded ConfigChangeType = "added"
+
+	// ConfigChanged is an existing key that gets a new value
+	ConfigChanged ConfigChangeType = "changed"
+
+	// ConfigRemoved is an existing key that is unset
+	ConfigRemoved ConfigChangeType = "removed"
+)
+
+// ConfigChange is a single key modified by a config update.
+type ConfigChange struct {
+	Type ConfigChangeType
+	Key  string
+}
+
+// ConfigDiff lists the keys modified by a config update, sorted by key.
+// Values are never part of the diff, so it is safe to print.
+type ConfigDiff struct {
+	Resource string
+	Changes  []*ConfigChange
+}
+
+// HasChanges returns whether or not applying the update will modify anything.
+func (d *ConfigDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// String returns a human readable version of the diff, with every value
+// redacted.
+func (d *ConfigDiff) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "resource %q:\n", d.Resource)
+
+	if len(d.Changes) == 0 {
+		buf.WriteString("  no changes\n")
+		return buf.String()
+	}
+
+	for _, c := range d.Changes {
+		switch c.Type {
+		case ConfigAdded:
+			fmt.Fprintf(buf, "  + %s = <redacted>\n", c.Key)
+		case ConfigChanged:
+			fmt.Fprintf(buf, "  ~ %s = <redacted>\n", c.Key)
+		case ConfigRemoved:
+			fmt.Fprintf(buf, "  - %s\n", c.Key)
+		}
+	}
+
+	return buf.String()
+}
+
+// ConfigUpdate describes the keys to set and unset on a config.
+type ConfigUpdate struct {
+	Set   map[string]string
+	Unset []string
+}
+
+// ConfigOpts holds the optional values for updating a config.
+type ConfigOpts struct {
+	// DryRun only computes the diff, the config is left untouched.
+	DryRun bool
+
+	// Output is where the diff is printed before it is applied. Nothing is
+	// printed when no output is given.
+	Output io.Writer
+}
+
+// UpdateResourceConfig sets and unsets keys of the config of the custom
+// resource with the given name. Every key is validated before anything is
+// sent to the API, and only the keys which actually change are patched.
+//
+// The returned diff describes the changes, and is returned as is when a
+// dry run is requested.
+func (c *Client) UpdateResourceConfig(ctx context.Context, project *string, name string, update *ConfigUpdate, opts *ConfigOpts) (*ConfigDiff, error) {
+	if opts == nil {
+		opts = &ConfigOpts{}
+	}
+
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+
+	res, err := c.GetResource(ctx, project, &primitives.Resource{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Body.Source != "custom" {
+		return nil, ErrResourceNotCustom
+	}
+
+	current, err := c.Client.Resources.GetConfig(ctx, res.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, patch := diffConfig(name, *current, update)
+
+	if opts.Output != nil {
+		if _, err := io.WriteString(opts.Output, diff.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DryRun || !diff.HasChanges() {
+		return diff, nil
+	}
+
+	if _, err := c.Client.Resources.UpdateConfig(ctx, res.ID, &patch); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// SetResourceConfig sets the given keys on the config of the custom resource
+// with the given name, leaving the other keys untouched.
+func (c *Client) SetResourceConfig(ctx context.Context, project *string, name string, values map[string]string, opts *ConfigOpts) (*ConfigDiff, error) {
+	return c.UpdateResourceConfig(ctx, project, name, &ConfigUpdate{Set: values}, opts)
+}
+
+// UnsetResourceConfig removes the given keys from the config of the custom
+// resource with the given name. Keys which aren't set are ignored.
+func (c *Client) UnsetResourceConfig(ctx context.Context, project *string, name string, keys []string, opts *ConfigOpts) (*ConfigDiff, error) {
+	return c.UpdateResourceConfig(ctx, project, name, &ConfigUpdate{Unset: keys}, opts)
+}
+
+// ImportResourceConfig reads a .env file and sets all of its keys on the
+// config of the custom resource with the given name.
+func (c *Client) ImportResourceConfig(ctx context.Context, project *string, name string, r io.Reader, opts *ConfigOpts) (*ConfigDiff, error) {
+	values, err := ParseDotEnv(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SetResourceConfig(ctx, project, name, values, opts)
+}
+
+func (u *ConfigUpdate) validate() error {
+	for _, k := range sortedConfigKeys(u.Set) {
+		if manifold.CredentialKey(k).Validate(nil) != nil {
+			return &ConfigError{Key: k, Err: ErrInvalidConfigKey}
+		}
+
+		if manifold.CredentialBody(u.Set[k]).Validate(nil) != nil {
+			return &ConfigError{Key: k, Err: ErrInvalidConfigValue}
+		}
+	}
+
+	for _, k := range u.Unset {
+		if manifold.CredentialKey(k).Validate(nil) != nil {
+			return &ConfigError{Key: k, Err: ErrInvalidConfigKey}
+		}
+	}
+
+	return nil
+}
+
+// diffConfig compares the update to the current config, and returns the diff
+// alongside the merge patch to send to the API.
+func diffConfig(name string, current map[string]string, update *ConfigUpdate) (*ConfigDiff, map[string]interface{}) {
+	diff := &ConfigDiff{Resource: name, Changes: []*ConfigChange{}}
+	patch := map[string]interface{}{}
+
+	for k, v := range update.Set {
+		old, ok := current[k]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, &ConfigChange{Type: ConfigAdded, Key: k})
+		case old != v:
+			diff.Changes = append(diff.Changes, &ConfigChange{Type: ConfigChanged, Key: k})
+		default:
+			continue
+		}
+		patch[k] = v
+	}
+
+	for _, k := range update.Unset {
+		if _, ok := current[k]; !ok {
+			continue
+		}
+		if _, ok := update.Set[k]; ok {
+			// The key is also set, the update is ambiguous so setting wins.
+			continue
+		}
+
+		diff.Changes = append(diff.Changes, &ConfigChange{Type: ConfigRemoved, Key: k})
+
+		// A null value removes the key from the config.
+		patch[k] = nil
+	}
+
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].Key < diff.Changes[j].Key
+	})
+
+	return diff, patch
+}
+
+// ParseDotEnv reads KEY=VALUE pairs in the .env format. Blank lines and
+// comments are ignored, and an optional `export` prefix is allowed. Values can
+// be double quoted, with \n, \r, \", \\ and \$ escapes, or single quoted, in
+// which case they are taken literally.
+func ParseDotEnv(r io.Reader) (map[string]string, error) {
+	values := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+
+		values[strings.TrimSpace(parts[0])] = value
+	}
+
+	return values, scanner.Err()
+}
+
+func parseDotEnvValue(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, `'`):
+		if len(v) < 2 || !strings.HasSuffix(v, `'`) {
+			return "", errors.New("unterminated single quoted value")
+		}
+		return v[1 : len(v)-1], nil
+	case strings.HasPrefix(v, `"`):
+		if len(v) < 2 || !strings.HasSuffix(v, `"`) {
+			return "", errors.New("unterminated double quoted value")
+		}
+		return unescapeDotEnv(v[1 : len(v)-1]), nil
+	default:
+		// Unquoted values can end with a comment.
+		if i := strings.Index(v, " #"); i >= 0 {
+			v = strings.TrimSpace(v[:i])
+		}
+		return v, nil
+	}
+}
+
+func unescapeDotEnv(v string) string {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i == len(v)-1 {
+			buf.WriteByte(v[i])
+			continue
+		}
+
+		i++
+		switch v[i] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case '"', '\\', '$':
+			buf.WriteByte(v[i])
+		default:
+			buf.WriteByte('\\')
+			buf.WriteByte(v[i])
+		}
+	}
+
+	return buf.String()
+}
+
+func sortedConfigKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
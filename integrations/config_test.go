@@ -0,0 +1,182 @@
+package integrations_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/integrations"
+)
+
+func TestUpdateResourceConfig(t *testing.T) {
+	ctx := context.Background()
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	pid := api.addProject("website", nil)
+	rid := api.addResource("stripe", nil, &pid, map[string]string{
+		"API_KEY": "old",
+		"UNUSED":  "value",
+	})
+
+	cl := api.client(t, nil)
+	project := strPtr("website")
+
+	t.Run("with a dry run", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		diff, err := cl.UpdateResourceConfig(ctx, project, "stripe", &integrations.ConfigUpdate{
+			Set:   map[string]string{"API_KEY": "new", "WEBHOOK_SECRET": "whsec"},
+			Unset: []string{"UNUSED", "MISSING"},
+		}, &integrations.ConfigOpts{DryRun: true, Output: out})
+		expectNoError(t, err)
+
+		expected := `resource "stripe":
+  ~ API_KEY = <redacted>
+  - UNUSED
+  + WEBHOOK_SECRET = <redacted>
+`
+		expectStringEqual(t, diff.String(), expected)
+		expectStringEqual(t, out.String(), expected)
+
+		if strings.Contains(out.String(), "new") || strings.Contains(out.String(), "whsec") {
+			t.Errorf("Expected values to be redacted, got '%s'", out)
+		}
+
+		if api.requestCount("PATCH") != 0 {
+			t.Error("Expected the config not to be patched")
+		}
+	})
+
+	t.Run("with new values", func(t *testing.T) {
+		diff, err := cl.SetResourceConfig(ctx, project, "stripe", map[string]string{
+			"API_KEY": "new",
+		}, nil)
+		expectNoError(t, err)
+
+		if len(diff.Changes) != 1 || diff.Changes[0].Type != integrations.ConfigChanged {
+			t.Fatalf("Expected a single change, got %+v", diff.Changes)
+		}
+		expectConfig(t, api, rid, map[string]string{"API_KEY": "new", "UNUSED": "value"})
+	})
+
+	t.Run("with unchanged values", func(t *testing.T) {
+		patches := api.requestCount("PATCH")
+
+		diff, err := cl.SetResourceConfig(ctx, project, "stripe", map[string]string{"API_KEY": "new"}, nil)
+		expectNoError(t, err)
+
+		if diff.HasChanges() || api.requestCount("PATCH") != patches {
+			t.Errorf("Expected nothing to be patched, got %+v", diff.Changes)
+		}
+	})
+
+	t.Run("with removed keys", func(t *testing.T) {
+		_, err := cl.UnsetResourceConfig(ctx, project, "stripe", []string{"UNUSED"}, nil)
+		expectNoError(t, err)
+		expectConfig(t, api, rid, map[string]string{"API_KEY": "new"})
+	})
+
+	t.Run("with a .env file", func(t *testing.T) {
+		env := "# stripe\nexport WEBHOOK_SECRET=\"wh\\$ec\"\nAPI_KEY='new'\n"
+		diff, err := cl.ImportResourceConfig(ctx, project, "stripe", strings.NewReader(env), nil)
+		expectNoError(t, err)
+
+		if len(diff.Changes) != 1 || diff.Changes[0].Key != "WEBHOOK_SECRET" {
+			t.Fatalf("Expected a single change, got %+v", diff.Changes)
+		}
+		expectConfig(t, api, rid, map[string]string{"API_KEY": "new", "WEBHOOK_SECRET": "wh$ec"})
+	})
+
+	t.Run("with an invalid key", func(t *testing.T) {
+		_, err := cl.SetResourceConfig(ctx, project, "stripe", map[string]string{"api-key": "x"}, nil)
+		expectConfigError(t, err, "api-key", integrations.ErrInvalidConfigKey)
+	})
+
+	t.Run("with a too long value", func(t *testing.T) {
+		_, err := cl.SetResourceConfig(ctx, project, "stripe", map[string]string{
+			"API_KEY": strings.Repeat("x", 1<<20),
+		}, nil)
+		expectConfigError(t, err, "API_KEY", integrations.ErrInvalidConfigValue)
+	})
+
+	t.Run("with a non-existing resource", func(t *testing.T) {
+		_, err := cl.SetResourceConfig(ctx, project, "missing", map[string]string{"API_KEY": "x"}, nil)
+		expectErrorEqual(t, err, integrations.ErrResourceNotFound)
+	})
+}
+
+func expectConfig(t *testing.T, api *fakeAPI, id manifold.ID, expected map[string]string) {
+	api.Lock()
+	defer api.Unlock()
+
+	if !reflect.DeepEqual(api.configs[id], expected) {
+		t.Fatalf("Expected config '%v', got '%v'", expected, api.configs[id])
+	}
+}
+
+func expectConfigError(t *testing.T, err error, key string, exp error) {
+	cerr, ok := err.(*integrations.ConfigError)
+	if !ok {
+		t.Fatalf("Expected a ConfigError, got '%v'", err)
+	}
+
+	if cerr.Key != key || cerr.Err != exp {
+		t.Fatalf("Expected error '%s: %s', got '%s'", key, exp, cerr)
+	}
+}
+
+func TestParseDotEnv(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "with unquoted values",
+			input:    "A=1\n\n# comment\nB = two # trailing\n",
+			expected: map[string]string{"A": "1", "B": "two"},
+		},
+		{
+			name:     "with quoted values",
+			input:    "A=\"multi\\nline \\\"quoted\\\"\"\nB='literal \\n'\n",
+			expected: map[string]string{"A": "multi\nline \"quoted\"", "B": "literal \\n"},
+		},
+		{
+			name:     "with an export prefix",
+			input:    "export A=1",
+			expected: map[string]string{"A": "1"},
+		},
+		{
+			name:  "with a missing value",
+			input: "A=1\nB\n",
+			err:   "line 2: expected KEY=VALUE",
+		},
+		{
+			name:  "with an unterminated quote",
+			input: "A=\"value",
+			err:   "line 1: unterminated double quoted value",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := integrations.ParseDotEnv(strings.NewReader(tc.input))
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("Expected error '%s', got '%v'", tc.err, err)
+				}
+				return
+			}
+
+			expectNoError(t, err)
+			if !reflect.DeepEqual(out, tc.expected) {
+				t.Errorf("Expected '%v', got '%v'", tc.expected, out)
+			}
+		})
+	}
+}
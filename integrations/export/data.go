@@ -0,0 +1,34 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// JSON writes the credentials as a JSON object.
+func JSON(w io.Writer, creds map[string]string) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(creds)
+}
+
+// YAML writes the credentials as a YAML mapping. Multiline values are
+// written as literal blocks.
+func YAML(w io.Writer, creds map[string]string) error {
+	if len(creds) == 0 {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}
+
+	b, err := yaml.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
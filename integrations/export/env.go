@@ -0,0 +1,64 @@
+package export
+
+import (
+	"io"
+	"strings"
+)
+
+var (
+	dotEnvReplacer = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		`$`, `\$`,
+		"\n", `\n`,
+		"\r", `\r`,
+	)
+
+	fishReplacer = strings.NewReplacer(
+		`\`, `\\`,
+		`'`, `\'`,
+	)
+)
+
+// DotEnv writes the credentials as a `.env` file. Values are double quoted,
+// with newlines escaped as `\n` so multiline values stay on a single line.
+func DotEnv(w io.Writer, creds map[string]string) error {
+	return writeLines(w, creds, func(k, v string) (string, error) {
+		return k + `="` + dotEnvReplacer.Replace(v) + `"`, nil
+	})
+}
+
+// Shell writes the credentials as POSIX `export` statements which can be
+// evaluated by any sh compatible shell. Values are single quoted, which keeps
+// multiline values intact.
+func Shell(w io.Writer, creds map[string]string) error {
+	return writeLines(w, creds, func(k, v string) (string, error) {
+		return "export " + k + "=" + shellQuote(v), nil
+	})
+}
+
+// Fish writes the credentials as `set -gx` statements for the fish shell.
+func Fish(w io.Writer, creds map[string]string) error {
+	return writeLines(w, creds, func(k, v string) (string, error) {
+		return "set -gx " + k + " '" + fishReplacer.Replace(v) + "'", nil
+	})
+}
+
+// Docker writes the credentials in the format expected by
+// `docker run --env-file`. Docker reads values literally up to the end of the
+// line, so no quoting is applied and multiline values result in an error.
+func Docker(w io.Writer, creds map[string]string) error {
+	return writeLines(w, creds, func(k, v string) (string, error) {
+		if strings.ContainsAny(v, "\r\n") {
+			return "", ErrMultilineValue
+		}
+
+		return k + "=" + v, nil
+	})
+}
+
+// shellQuote single quotes a value, the only character that needs special
+// treatment is the single quote itself.
+func shellQuote(v string) string {
+	return "'" + strings.Replace(v, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,122 @@
+// Package export writes flattened credentials in the formats used to hand
+// them over to other tools, like env files, shell scripts or Kubernetes
+// Secrets.
+//
+// Credentials are expected to be flattened first, see
+// integrations.FlattenResourcesCredentialValues. Keys are always written in
+// sorted order so the output is stable.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// Format is the name of a supported output format.
+type Format string
+
+// All the supported formats
+const (
+	FormatDotEnv     Format = "dotenv"
+	FormatShell      Format = "shell"
+	FormatFish       Format = "fish"
+	FormatJSON       Format = "json"
+	FormatYAML       Format = "yaml"
+	FormatDocker     Format = "docker"
+	FormatKubernetes Format = "kubernetes"
+)
+
+// Human friendly error values
+var (
+	ErrUnknownFormat  = errors.New("unknown export format")
+	ErrInvalidKey     = errors.New("key can't be used as an environment variable name")
+	ErrMultilineValue = errors.New("multiline values can't be represented in this format")
+)
+
+var envKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Encoder writes a set of credentials to a writer.
+type Encoder interface {
+	Encode(w io.Writer, creds map[string]string) error
+}
+
+// EncoderFunc allows a plain function to be used as an Encoder.
+type EncoderFunc func(w io.Writer, creds map[string]string) error
+
+// Encode implements the Encoder interface
+func (f EncoderFunc) Encode(w io.Writer, creds map[string]string) error {
+	return f(w, creds)
+}
+
+// Encoders maps every format that doesn't need extra configuration to its
+// Encoder. The Kubernetes format requires a name, see KubernetesSecret.
+var Encoders = map[Format]Encoder{
+	FormatDotEnv: EncoderFunc(DotEnv),
+	FormatShell:  EncoderFunc(Shell),
+	FormatFish:   EncoderFunc(Fish),
+	FormatJSON:   EncoderFunc(JSON),
+	FormatYAML:   EncoderFunc(YAML),
+	FormatDocker: EncoderFunc(Docker),
+}
+
+// Write encodes the credentials in the given format.
+func Write(w io.Writer, format Format, creds map[string]string) error {
+	enc, ok := Encoders[format]
+	if !ok {
+		return ErrUnknownFormat
+	}
+
+	return enc.Encode(w, creds)
+}
+
+// KeyError is returned when a key or its value can't be represented in the
+// requested format.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+// Error implements the error interface
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Err)
+}
+
+func sortedKeys(creds map[string]string) []string {
+	keys := make([]string, 0, len(creds))
+	for k := range creds {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// writeLines validates all keys as environment variable names and writes a
+// line per credential. Nothing is written if a key is invalid.
+func writeLines(w io.Writer, creds map[string]string, line func(k, v string) (string, error)) error {
+	keys := sortedKeys(creds)
+	lines := make([]string, len(keys))
+
+	for i, k := range keys {
+		if !envKeyRegex.MatchString(k) {
+			return &KeyError{Key: k, Err: ErrInvalidKey}
+		}
+
+		l, err := line(k, creds[k])
+		if err != nil {
+			return &KeyError{Key: k, Err: err}
+		}
+		lines[i] = l
+	}
+
+	for _, l := range lines {
+		if _, err := io.WriteString(w, l+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var creds = map[string]string{
+	"TOKEN":       "abc123",
+	"PRIVATE_KEY": "-----BEGIN KEY-----\nline 'two'\n-----END KEY-----",
+	"PASSWORD":    `p"a$s\w'rd`,
+}
+
+func TestWrite(t *testing.T) {
+	tcs := []struct {
+		format Format
+		expect string
+	}{
+		{
+			format: FormatDotEnv,
+			expect: `PASSWORD="p\"a\$s\\w'rd"
+PRIVATE_KEY="-----BEGIN KEY-----\nline 'two'\n-----END KEY-----"
+TOKEN="abc123"
+`,
+		},
+		{
+			format: FormatShell,
+			expect: `export PASSWORD='p"a$s\w'\''rd'
+export PRIVATE_KEY='-----BEGIN KEY-----
+line '\''two'\''
+-----END KEY-----'
+export TOKEN='abc123'
+`,
+		},
+		{
+			format: FormatFish,
+			expect: `set -gx PASSWORD 'p"a$s\\w\'rd'
+set -gx PRIVATE_KEY '-----BEGIN KEY-----
+line \'two\'
+-----END KEY-----'
+set -gx TOKEN 'abc123'
+`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(string(tc.format), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Write(buf, tc.format, creds); err != nil {
+				t.Fatalf("Expected no error, got '%s'", err)
+			}
+
+			if buf.String() != tc.expect {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expect, buf.String())
+			}
+		})
+	}
+
+	t.Run("with an unknown format", func(t *testing.T) {
+		if err := Write(&bytes.Buffer{}, Format("toml"), creds); err != ErrUnknownFormat {
+			t.Errorf("Expected '%s', got '%v'", ErrUnknownFormat, err)
+		}
+	})
+
+	t.Run("with an invalid key", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := Write(buf, FormatDotEnv, map[string]string{"A": "1", "NOT-VALID": "2"})
+
+		kerr, ok := err.(*KeyError)
+		if !ok || kerr.Key != "NOT-VALID" || kerr.Err != ErrInvalidKey {
+			t.Errorf("Expected a KeyError for 'NOT-VALID', got '%v'", err)
+		}
+
+		if buf.Len() != 0 {
+			t.Errorf("Expected nothing to be written, got '%s'", buf.String())
+		}
+	})
+}
+
+func TestShell_RoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Shell(buf, creds); err != nil {
+		t.Fatalf("Expected no error, got '%s'", err)
+	}
+
+	for k, v := range creds {
+		out, err := exec.Command("sh", "-c", buf.String()+`printf '%s' "$`+k+`"`).Output()
+		if err != nil {
+			t.Fatalf("Expected the script to run, got '%s'", err)
+		}
+
+		if string(out) != v {
+			t.Errorf("Expected %s to equal %q, got %q", k, v, out)
+		}
+	}
+}
+
+func TestDocker(t *testing.T) {
+	t.Run("with single line values", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := Docker(buf, map[string]string{"URL": "postgres://u:p@host/db?ssl=true"})
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+
+		if e := "URL=postgres://u:p@host/db?ssl=true\n"; buf.String() != e {
+			t.Errorf("Expected %q, got %q", e, buf.String())
+		}
+	})
+
+	t.Run("with a multiline value", func(t *testing.T) {
+		err := Docker(&bytes.Buffer{}, creds)
+		kerr, ok := err.(*KeyError)
+		if !ok || kerr.Key != "PRIVATE_KEY" || kerr.Err != ErrMultilineValue {
+			t.Errorf("Expected a KeyError for 'PRIVATE_KEY', got '%v'", err)
+		}
+	})
+}
+
+func TestJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := JSON(buf, creds); err != nil {
+		t.Fatalf("Expected no error, got '%s'", err)
+	}
+
+	out := map[string]string{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Expected valid JSON, got '%s'", err)
+	}
+
+	if !reflect.DeepEqual(out, creds) {
+		t.Errorf("Expected %v, got %v", creds, out)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := YAML(buf, creds); err != nil {
+		t.Fatalf("Expected no error, got '%s'", err)
+	}
+
+	out := map[string]string{}
+	if err := yaml.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Expected valid YAML, got '%s'", err)
+	}
+
+	if !reflect.DeepEqual(out, creds) {
+		t.Errorf("Expected %v, got %v", creds, out)
+	}
+}
+
+func TestKubernetesSecret(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := KubernetesSecret("website", "production").Encode(buf, map[string]string{
+		"TOKEN": "abc123",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%s'", err)
+	}
+
+	expect := strings.Join([]string{
+		"apiVersion: v1",
+		"kind: Secret",
+		"metadata:",
+		"  name: website",
+		"  namespace: production",
+		"type: Opaque",
+		"data:",
+		"  TOKEN: " + base64.StdEncoding.EncodeToString([]byte("abc123")),
+		"",
+	}, "\n")
+
+	if buf.String() != expect {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expect, buf.String())
+	}
+
+	t.Run("with an invalid key", func(t *testing.T) {
+		err := KubernetesSecret("website", "").Encode(&bytes.Buffer{}, map[string]string{"A KEY": "1"})
+		if kerr, ok := err.(*KeyError); !ok || kerr.Err != ErrInvalidSecretKey {
+			t.Errorf("Expected a KeyError, got '%v'", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"regexp"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ErrInvalidSecretKey is returned when a key can't be used in a Kubernetes
+// Secret.
+var ErrInvalidSecretKey = errors.New("key can't be used in a Kubernetes Secret")
+
+var secretKeyRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+type secretMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type secret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   secretMetadata    `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// KubernetesSecret returns an Encoder that writes the credentials as an
+// Opaque `v1/Secret` manifest, with the values base64 encoded in its data.
+// The namespace is optional.
+func KubernetesSecret(name, namespace string) Encoder {
+	return EncoderFunc(func(w io.Writer, creds map[string]string) error {
+		s := secret{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata: secretMetadata{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: "Opaque",
+			Data: make(map[string]string, len(creds)),
+		}
+
+		for k, v := range creds {
+			if !secretKeyRegex.MatchString(k) {
+				return &KeyError{Key: k, Err: ErrInvalidSecretKey}
+			}
+			s.Data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+
+		b, err := yaml.Marshal(s)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(b)
+		return err
+	})
+}
@@ -0,0 +1,323 @@
+package integrations_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/gateway"
+	"github.com/manifoldco/go-manifold/idtype"
+	"github.com/manifoldco/go-manifold/integrations"
+)
+
+// fakeAPI is an in memory implementation of the endpoints used by the
+// integrations package. Marketplace and identity requests are served under
+// their service name, gateway requests under `/gateway`.
+type fakeAPI struct {
+	sync.Mutex
+	*httptest.Server
+
+	teams       []manifold.Team
+	projects    []manifold.Project
+	resources   []manifold.Resource
+	credentials []manifold.Credential
+	configs     map[manifold.ID]map[string]string
+	products    []gateway.ResolvedProduct
+
+	requests []string
+	failing  bool
+}
+
+func newFakeAPI() *fakeAPI {
+	api := &fakeAPI{configs: map[manifold.ID]map[string]string{}}
+	api.Server = httptest.NewServer(http.HandlerFunc(api.serve))
+	return api
+}
+
+// client returns an integrations client pointed at the fake API.
+// manifoldClient returns a manifold client for the fake API.
+func (api *fakeAPI) manifoldClient() *manifold.Client {
+	return manifold.New(manifold.ForURLPattern(api.URL + "/%s"))
+}
+
+func (api *fakeAPI) client(t *testing.T, team *string) *integrations.Client {
+	mc := api.manifoldClient()
+
+	// The gateway client always uses the production URL, so we route its
+	// requests to the fake server at the transport level.
+	dt := http.DefaultTransport
+	http.DefaultTransport = rtFunc(func(r *http.Request) (*http.Response, error) {
+		r.URL.Scheme = "http"
+		r.URL.Host = strings.TrimPrefix(api.URL, "http://")
+		r.URL.Path = "/gateway" + strings.TrimPrefix(r.URL.Path, "/v1")
+		return dt.RoundTrip(r)
+	})
+	gw := gateway.New()
+	http.DefaultTransport = dt
+
+	c, err := integrations.NewClient(mc, team)
+	if err != nil {
+		t.Fatalf("Could not set up the fake client: %s", err)
+	}
+	c.Gateway = gw
+
+	return c
+}
+
+func (api *fakeAPI) addTeam(label string) manifold.ID {
+	api.Lock()
+	defer api.Unlock()
+
+	t := manifold.Team{ID: manifold.MustNewID(idtype.Team)}
+	t.Body.Label = label
+	t.Body.Name = label
+	api.teams = append(api.teams, t)
+	return t.ID
+}
+
+func (api *fakeAPI) addProject(label string, team *manifold.ID) manifold.ID {
+	api.Lock()
+	defer api.Unlock()
+
+	p := manifold.Project{ID: manifold.MustNewID(idtype.Project)}
+	p.Body.Label = label
+	p.Body.Name = label
+	p.Body.TeamID = team
+	api.projects = append(api.projects, p)
+	return p.ID
+}
+
+func (api *fakeAPI) addResource(label string, team, project *manifold.ID, values map[string]string) manifold.ID {
+	api.Lock()
+	defer api.Unlock()
+
+	r := manifold.Resource{ID: manifold.MustNewID(idtype.Resource)}
+	r.Body.Label = label
+	r.Body.Name = label
+	r.Body.TeamID = team
+	r.Body.ProjectID = project
+	r.Body.Source = "custom"
+	api.resources = append(api.resources, r)
+
+	if values != nil {
+		c := manifold.Credential{ID: manifold.MustNewID(idtype.Credential)}
+		c.Body.ResourceID = r.ID
+		c.Body.Values = values
+		api.credentials = append(api.credentials, c)
+		api.configs[r.ID] = values
+	}
+
+	return r.ID
+}
+
+func (api *fakeAPI) setValues(id manifold.ID, values map[string]string) {
+	api.Lock()
+	defer api.Unlock()
+
+	for i, c := range api.credentials {
+		if c.Body.ResourceID == id {
+			api.credentials[i].Body.Values = values
+		}
+	}
+	api.configs[id] = values
+}
+
+func (api *fakeAPI) setFailing(failing bool) {
+	api.Lock()
+	defer api.Unlock()
+
+	api.failing = failing
+}
+
+func (api *fakeAPI) requestCount(prefix string) int {
+	api.Lock()
+	defer api.Unlock()
+
+	n := 0
+	for _, r := range api.requests {
+		if strings.HasPrefix(r, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (api *fakeAPI) serve(w http.ResponseWriter, r *http.Request) {
+	api.Lock()
+	defer api.Unlock()
+
+	api.requests = append(api.requests, r.Method+" "+r.URL.Path)
+	if api.failing {
+		w.WriteHeader(http.StatusInternalServerError)
+		writeJSON(w, manifold.NewError("internal", "unavailable"))
+		return
+	}
+
+	q := r.URL.Query()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.URL.Path == "/identity/teams":
+		writeJSON(w, api.teams)
+
+	case r.URL.Path == "/marketplace/projects" && r.Method == http.MethodGet:
+		out := []manifold.Project{}
+		for _, p := range api.projects {
+			if l := q.Get("label"); l != "" && p.Body.Label != l {
+				continue
+			}
+			if !matchID(q.Get("team_id"), p.Body.TeamID) {
+				continue
+			}
+			out = append(out, p)
+		}
+		writeJSON(w, out)
+
+	case r.URL.Path == "/marketplace/projects" && r.Method == http.MethodPost:
+		req := manifold.CreateProject{}
+		json.NewDecoder(r.Body).Decode(&req)
+		p := manifold.Project{ID: manifold.MustNewID(idtype.Project)}
+		p.Body.Label = req.Body.Label
+		p.Body.Name = req.Body.Name
+		p.Body.TeamID = req.Body.TeamID
+		api.projects = append(api.projects, p)
+		writeJSON(w, p)
+
+	case r.URL.Path == "/marketplace/resources/":
+		out := []manifold.Resource{}
+		for _, res := range api.resources {
+			if l := q.Get("label"); l != "" && res.Body.Label != l {
+				continue
+			}
+			if !matchID(q.Get("team_id"), res.Body.TeamID) || !matchID(q.Get("project_id"), res.Body.ProjectID) {
+				continue
+			}
+			out = append(out, res)
+		}
+		writeJSON(w, out)
+
+	case len(parts) == 4 && parts[1] == "resources" && parts[3] == "config":
+		id, _ := manifold.DecodeIDFromString(parts[2])
+		cfg, ok := api.configs[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			writeJSON(w, manifold.NewError("not_found", "resource not found"))
+			return
+		}
+
+		if r.Method == http.MethodPatch {
+			patch := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&patch)
+			next := map[string]string{}
+			for k, v := range cfg {
+				next[k] = v
+			}
+			for k, v := range patch {
+				if v == nil {
+					delete(next, k)
+					continue
+				}
+				next[k] = fmt.Sprint(v)
+			}
+			cfg = next
+			api.configs[id] = cfg
+			for i, c := range api.credentials {
+				if c.Body.ResourceID == id {
+					api.credentials[i].Body.Values = cfg
+				}
+			}
+		}
+		writeJSON(w, cfg)
+
+	case r.URL.Path == "/marketplace/credentials":
+		ids := q["resource_id"]
+		pid := q.Get("project_id")
+		out := []manifold.Credential{}
+		for _, c := range api.credentials {
+			if len(ids) > 0 && !containsString(ids, c.Body.ResourceID.String()) {
+				continue
+			}
+			if pid != "" && !api.resourceInProject(c.Body.ResourceID, pid) {
+				continue
+			}
+			out = append(out, c)
+		}
+		writeJSON(w, out)
+
+	case len(parts) == 3 && parts[0] == "gateway" && parts[1] == "product":
+		for _, p := range api.products {
+			if p.Label == parts[2] {
+				writeJSON(w, p)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, gateway.Error{Type: "error", Code: "404", Class: "not_found", Message: "product not found"})
+
+	case r.URL.Path == "/gateway/resource":
+		req := gateway.ResourceCreateRequest{}
+		json.NewDecoder(r.Body).Decode(&req)
+		res := manifold.Resource{ID: manifold.MustNewID(idtype.Resource)}
+		res.Body.Label = *req.Label
+		res.Body.ProjectID = req.ProjectID
+		res.Body.ProductID = req.ProductID
+		res.Body.PlanID = req.PlanID
+		res.Body.Source = req.Source
+		if req.Owner != nil {
+			res.Body.TeamID = &req.Owner.ID
+		}
+		api.resources = append(api.resources, res)
+		writeJSON(w, gateway.Resource{ID: res.ID})
+
+	case len(parts) == 4 && parts[0] == "gateway" && parts[2] == "resource":
+		id, _ := manifold.DecodeIDFromString(parts[3])
+		req := gateway.ResourceUpdateRequest{}
+		json.NewDecoder(r.Body).Decode(&req)
+		for i, res := range api.resources {
+			if res.ID == id {
+				api.resources[i].Body.ProjectID = req.ProjectID
+			}
+		}
+		writeJSON(w, gateway.Resource{ID: id})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, manifold.NewError("not_found", r.URL.Path))
+	}
+}
+
+func (api *fakeAPI) resourceInProject(id manifold.ID, pid string) bool {
+	for _, res := range api.resources {
+		if res.ID == id {
+			return matchID(pid, res.Body.ProjectID)
+		}
+	}
+	return false
+}
+
+func matchID(query string, id *manifold.ID) bool {
+	return query == "" || (id != nil && id.String() == query)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+type rtFunc func(*http.Request) (*http.Response, error)
+
+func (rt rtFunc) RoundTrip(r *http.Request) (*http.Response, error) { return rt(r) }
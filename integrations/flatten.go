@@ -1,11 +1,66 @@
 package integrations
 
 import (
+	"bytes"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
 
 	"github.com/manifoldco/go-manifold/integrations/primitives"
 )
 
+// ConflictPolicy decides what happens when two credentials flatten to the
+// same key.
+type ConflictPolicy int
+
+const (
+	// ConflictError fails the flattening. This is the default.
+	ConflictError ConflictPolicy = iota
+
+	// ConflictFirstWins keeps the value of the first resource, in resource
+	// name order.
+	ConflictFirstWins
+
+	// ConflictLastWins keeps the value of the last resource, in resource name
+	// order.
+	ConflictLastWins
+
+	// ConflictAutoPrefix prefixes every conflicting key with the name of its
+	// resource, like POSTGRES_DATABASE_URL. Aliased keys are never prefixed,
+	// so conflicting aliases fail the flattening, as do prefixed keys which
+	// still conflict.
+	ConflictAutoPrefix
+)
+
+// FlattenOpts holds the optional values for flattening credentials.
+//
+// Credentials with a `Name` alias always use it verbatim as their key; the
+// KeyTemplate, Prefixes and ConflictAutoPrefix only apply to the other
+// credentials.
+type FlattenOpts struct {
+	// KeyTemplate is a text/template used to build the keys, like
+	// `{{.Resource | upper}}_{{.Key}}`. It has access to the Resource and Key
+	// fields and to the upper, lower and env functions, env turning a value
+	// into a valid environment variable name. It defaults to `{{.Key}}`.
+	KeyTemplate string
+
+	// Prefixes are prepended to the keys of the resource they're set for.
+	Prefixes map[string]string
+
+	// Conflict is the policy applied when two credentials share a key.
+	Conflict ConflictPolicy
+}
+
+var invalidEnvChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+var keyTemplateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"env":   envName,
+}
+
 // FlattenResourceCredentialValues will take a set of CredentialValues that are
 // linked to a Resource and make a single key/value map. It will use the `Name`
 // as key if one is provided and `Default` as value if no value is present and
@@ -23,17 +78,52 @@ func FlattenResourceCredentialValues(resourceCredentials []*primitives.Credentia
 // `Name` as key if one is provided and `Default` as value if no value is
 // present and a default is set.
 func FlattenResourcesCredentialValues(resourcesCredentials map[string][]*primitives.CredentialValue) (map[string]string, error) {
-	creds := map[string]string{}
+	return FlattenResourcesCredentialValuesWithOpts(resourcesCredentials, nil)
+}
 
-	for _, set := range resourcesCredentials {
-		for _, cred := range set {
-			key := cred.Key
-			if cred.Name != "" {
-				key = cred.Name
-			}
+// FlattenResourcesCredentialValuesWithOpts is like
+// FlattenResourcesCredentialValues, but allows configuring how keys are named
+// and how conflicts are resolved. Resources are processed in name order and
+// credentials in key order, so the outcome of a conflict is deterministic.
+func FlattenResourcesCredentialValuesWithOpts(resourcesCredentials map[string][]*primitives.CredentialValue, opts *FlattenOpts) (map[string]string, error) {
+	if opts == nil {
+		opts = &FlattenOpts{}
+	}
+
+	tmpl, err := keyTemplate(opts.KeyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		resource string
+		key      string
+		value    string
+		alias    bool
+		prefixed bool
+	}
 
-			if _, ok := creds[key]; ok {
-				return nil, fmt.Errorf("key '%s' is already used, please us an alias", key)
+	resources := make([]string, 0, len(resourcesCredentials))
+	for r := range resourcesCredentials {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	entries := []*entry{}
+	used := map[string]int{}
+	for _, r := range resources {
+		set := make([]*primitives.CredentialValue, len(resourcesCredentials[r]))
+		copy(set, resourcesCredentials[r])
+		sort.SliceStable(set, func(i, j int) bool { return set[i].Key < set[j].Key })
+
+		for _, cred := range set {
+			key := cred.Name
+			if key == "" {
+				key, err = flattenKey(tmpl, r, cred.Key)
+				if err != nil {
+					return nil, err
+				}
+				key = opts.Prefixes[r] + key
 			}
 
 			value := cred.Value
@@ -41,9 +131,61 @@ func FlattenResourcesCredentialValues(resourcesCredentials map[string][]*primiti
 				value = cred.Default
 			}
 
-			creds[key] = value
+			entries = append(entries, &entry{resource: r, key: key, value: value, alias: cred.Name != ""})
+			used[key]++
+		}
+	}
+
+	creds := map[string]string{}
+	owners := map[string]*entry{}
+	for _, e := range entries {
+		key := e.key
+		if used[key] > 1 && opts.Conflict == ConflictAutoPrefix && !e.alias && e.resource != "" {
+			key = envName(e.resource) + "_" + key
+			e.prefixed = true
+		}
+
+		if prev, ok := owners[key]; ok {
+			switch {
+			case opts.Conflict == ConflictFirstWins:
+				continue
+			case opts.Conflict == ConflictLastWins:
+			case e.prefixed || prev.prefixed:
+				return nil, fmt.Errorf("key '%s' is still used after prefixing it with the resource name", key)
+			case e.alias || prev.alias:
+				return nil, fmt.Errorf("alias '%s' is already used", key)
+			default:
+				return nil, fmt.Errorf("key '%s' is already used, please use an alias", key)
+			}
 		}
+
+		owners[key] = e
+		creds[key] = e.value
 	}
 
 	return creds, nil
 }
+
+func keyTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	return template.New("key").Option("missingkey=error").Funcs(keyTemplateFuncs).Parse(text)
+}
+
+func flattenKey(tmpl *template.Template, resource, key string) (string, error) {
+	if tmpl == nil {
+		return key, nil
+	}
+
+	buf := &bytes.Buffer{}
+	err := tmpl.Execute(buf, struct{ Resource, Key string }{resource, key})
+	return buf.String(), err
+}
+
+// envName turns a value, like a resource label, into a valid environment
+// variable name.
+func envName(s string) string {
+	return invalidEnvChars.ReplaceAllString(strings.ToUpper(s), "_")
+}
@@ -0,0 +1,162 @@
+package integrations_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+func credValue(key, value string) *primitives.CredentialValue {
+	return &primitives.CredentialValue{
+		Credential: primitives.Credential{Key: key},
+		Value:      value,
+	}
+}
+
+func TestFlattenResourcesCredentialValuesWithOpts(t *testing.T) {
+	creds := map[string][]*primitives.CredentialValue{
+		"postgres": {credValue("DATABASE_URL", "pg"), credValue("PORT", "5432")},
+		"my-mysql": {credValue("DATABASE_URL", "mysql")},
+	}
+
+	tcs := []struct {
+		name     string
+		opts     *integrations.FlattenOpts
+		expected map[string]string
+		err      string
+	}{
+		{
+			name: "without options",
+			err:  "key 'DATABASE_URL' is already used, please use an alias",
+		},
+		{
+			name: "with first wins",
+			opts: &integrations.FlattenOpts{Conflict: integrations.ConflictFirstWins},
+			expected: map[string]string{
+				"DATABASE_URL": "mysql",
+				"PORT":         "5432",
+			},
+		},
+		{
+			name: "with last wins",
+			opts: &integrations.FlattenOpts{Conflict: integrations.ConflictLastWins},
+			expected: map[string]string{
+				"DATABASE_URL": "pg",
+				"PORT":         "5432",
+			},
+		},
+		{
+			name: "with auto prefix",
+			opts: &integrations.FlattenOpts{Conflict: integrations.ConflictAutoPrefix},
+			expected: map[string]string{
+				"MY_MYSQL_DATABASE_URL": "mysql",
+				"POSTGRES_DATABASE_URL": "pg",
+				"PORT":                  "5432",
+			},
+		},
+		{
+			name: "with a key template",
+			opts: &integrations.FlattenOpts{KeyTemplate: "{{.Resource | env}}_{{.Key}}"},
+			expected: map[string]string{
+				"MY_MYSQL_DATABASE_URL": "mysql",
+				"POSTGRES_DATABASE_URL": "pg",
+				"POSTGRES_PORT":         "5432",
+			},
+		},
+		{
+			name: "with a resource prefix",
+			opts: &integrations.FlattenOpts{Prefixes: map[string]string{"my-mysql": "LEGACY_"}},
+			expected: map[string]string{
+				"LEGACY_DATABASE_URL": "mysql",
+				"DATABASE_URL":        "pg",
+				"PORT":                "5432",
+			},
+		},
+		{
+			name: "with an invalid key template",
+			opts: &integrations.FlattenOpts{KeyTemplate: "{{.Missing}}"},
+			err:  `template: key:1:2: executing "key" at <.Missing>`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := integrations.FlattenResourcesCredentialValuesWithOpts(creds, tc.opts)
+			if tc.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+					t.Fatalf("Expected error '%s', got '%v'", tc.err, err)
+				}
+				return
+			}
+
+			expectNoError(t, err)
+			if !reflect.DeepEqual(out, tc.expected) {
+				t.Errorf("Expected '%v', got '%v'", tc.expected, out)
+			}
+		})
+	}
+
+	t.Run("with an alias", func(t *testing.T) {
+		aliased := credValue("DATABASE_URL", "mysql")
+		aliased.Name = "MYSQL_URL"
+
+		out, err := integrations.FlattenResourcesCredentialValuesWithOpts(map[string][]*primitives.CredentialValue{
+			"postgres": {credValue("DATABASE_URL", "pg")},
+			"mysql":    {aliased},
+		}, &integrations.FlattenOpts{KeyTemplate: "{{.Key | lower}}"})
+		expectNoError(t, err)
+
+		expected := map[string]string{"MYSQL_URL": "mysql", "database_url": "pg"}
+		if !reflect.DeepEqual(out, expected) {
+			t.Errorf("Expected '%v', got '%v'", expected, out)
+		}
+	})
+
+	t.Run("with an alias and auto prefix", func(t *testing.T) {
+		aliased := credValue("URL", "mysql")
+		aliased.Name = "DATABASE_URL"
+
+		out, err := integrations.FlattenResourcesCredentialValuesWithOpts(map[string][]*primitives.CredentialValue{
+			"postgres": {credValue("DATABASE_URL", "pg")},
+			"mysql":    {aliased},
+		}, &integrations.FlattenOpts{Conflict: integrations.ConflictAutoPrefix})
+		expectNoError(t, err)
+
+		expected := map[string]string{"DATABASE_URL": "mysql", "POSTGRES_DATABASE_URL": "pg"}
+		if !reflect.DeepEqual(out, expected) {
+			t.Errorf("Expected '%v', got '%v'", expected, out)
+		}
+	})
+
+	t.Run("with conflicting aliases and auto prefix", func(t *testing.T) {
+		a, b := credValue("URL", "mysql"), credValue("URL", "pg")
+		a.Name, b.Name = "DATABASE_URL", "DATABASE_URL"
+
+		_, err := integrations.FlattenResourcesCredentialValuesWithOpts(map[string][]*primitives.CredentialValue{
+			"mysql":    {a},
+			"postgres": {b},
+		}, &integrations.FlattenOpts{Conflict: integrations.ConflictAutoPrefix})
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+		expectStringEqual(t, err.Error(), "alias 'DATABASE_URL' is already used")
+	})
+
+	t.Run("with a prefixed key conflict", func(t *testing.T) {
+		aliased := credValue("URL", "legacy")
+		aliased.Name = "POSTGRES_DATABASE_URL"
+
+		_, err := integrations.FlattenResourcesCredentialValuesWithOpts(map[string][]*primitives.CredentialValue{
+			"legacy":   {aliased},
+			"mysql":    {credValue("DATABASE_URL", "mysql")},
+			"postgres": {credValue("DATABASE_URL", "pg")},
+		}, &integrations.FlattenOpts{Conflict: integrations.ConflictAutoPrefix})
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+		expectStringEqual(t, err.Error(), "key 'POSTGRES_DATABASE_URL' is still used after prefixing it with the resource name")
+	})
+}
@@ -0,0 +1,33 @@
+package integrations
+
+import (
+	"context"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+	"github.com/manifoldco/go-manifold/names"
+)
+
+// ResourceLabelExists returns whether the team the client is bound to has a
+// resource with the given label, in any of its projects.
+func (c *Client) ResourceLabelExists(ctx context.Context, label manifold.Label) (bool, error) {
+	teamID, err := c.teamID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	resources, err := c.listResources(ctx, &manifold.ResourcesListOpts{TeamID: teamID},
+		[]*primitives.Resource{{Name: string(label)}})
+	if err != nil {
+		return false, err
+	}
+
+	return len(resources) > 0, nil
+}
+
+// AllocateResourceLabel returns a free label for a new resource of the
+// product, derived from the resource ID like names.ForResource. Alternative
+// labels are tried when it is already used within the team.
+func (c *Client) AllocateResourceLabel(ctx context.Context, product manifold.Label, id manifold.ID) (manifold.Label, error) {
+	return names.NewAllocator(c.ResourceLabelExists).ForResource(ctx, product, id)
+}
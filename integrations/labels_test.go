@@ -0,0 +1,37 @@
+package integrations_test
+
+import (
+	"context"
+	"testing"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/names"
+)
+
+func TestAllocateResourceLabel(t *testing.T) {
+	ctx := context.Background()
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	rid, _ := manifold.DecodeIDFromString("268d37vht44f1e3t0n07jjx4d0qe8")
+	product := manifold.Label("degraffdb")
+	taken := names.ForResource(product, rid)
+
+	pid := api.addProject("website", nil)
+	api.addResource(string(taken), nil, &pid, nil)
+
+	cl := api.client(t, nil)
+
+	t.Run("with a free label", func(t *testing.T) {
+		label, err := cl.AllocateResourceLabel(ctx, "other", rid)
+		expectNoError(t, err)
+		expectStringEqual(t, string(label), string(names.ForResource("other", rid)))
+	})
+
+	t.Run("with a taken label", func(t *testing.T) {
+		label, err := cl.AllocateResourceLabel(ctx, product, rid)
+		expectNoError(t, err)
+		expectStringEqual(t, string(label), string(names.Alternative(product, rid, 1)))
+	})
+}
@@ -0,0 +1,74 @@
+package integrations
+
+import (
+	"sync"
+	"time"
+
+	manifold "github.com/manifoldco/go-manifold"
+)
+
+// lookupCache holds the IDs and resources the client looked up by label. Its
+// entries are indexed by a key describing the lookup, see teamLabelKey,
+// projectKey and resourceKey, and expire after the TTL they were set with.
+type lookupCache struct {
+	sync.RWMutex
+	entries map[string]lookupEntry
+}
+
+type lookupEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newLookupCache() *lookupCache {
+	return &lookupCache{entries: map[string]lookupEntry{}}
+}
+
+func (lc *lookupCache) get(key string) (interface{}, bool) {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	e, ok := lc.entries[key]
+	if !ok || !e.expires.IsZero() && time.Now().After(e.expires) {
+		return nil, false
+	}
+
+	return e.value, true
+}
+
+// set caches the value for the key. A zero TTL caches it until it is
+// invalidated.
+func (lc *lookupCache) set(key string, value interface{}, ttl time.Duration) {
+	lc.Lock()
+	defer lc.Unlock()
+
+	e := lookupEntry{value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	lc.entries[key] = e
+}
+
+// invalidate drops every entry whose key matches.
+func (lc *lookupCache) invalidate(match func(key string) bool) {
+	lc.Lock()
+	defer lc.Unlock()
+
+	for k := range lc.entries {
+		if match(k) {
+			delete(lc.entries, k)
+		}
+	}
+}
+
+func teamLabelKey(label string) string {
+	return "team/" + label
+}
+
+func projectKey(teamID *manifold.ID, label string) string {
+	return "project/" + idKey(teamID) + "/" + label
+}
+
+func resourceKey(teamID, projectID *manifold.ID, label string) string {
+	return "resource/" + idKey(teamID) + "/" + idKey(projectID) + "/" + label
+}
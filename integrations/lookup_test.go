@@ -0,0 +1,131 @@
+package integrations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+func TestLookupCache(t *testing.T) {
+	ctx := context.Background()
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	pid := api.addProject("website", nil)
+	api.addResource("db", nil, &pid, map[string]string{"DATABASE_URL": "pg"})
+	api.addResource("cache", nil, &pid, map[string]string{"REDIS_URL": "redis"})
+
+	project := strPtr("website")
+	res := []*primitives.Resource{{Name: "db"}}
+
+	t.Run("with repeated lookups", func(t *testing.T) {
+		cl := api.client(t, nil)
+		cl.LookupTTL = time.Minute
+
+		for i := 0; i < 3; i++ {
+			rs, err := cl.GetResources(ctx, project, res)
+			expectNoError(t, err)
+			expectStringEqual(t, rs[0].Body.Label, "db")
+		}
+
+		if n := api.requestCount("GET /marketplace/resources/"); n != 1 {
+			t.Errorf("Expected a single resources request, got '%d'", n)
+		}
+		if n := api.requestCount("GET /marketplace/projects"); n != 1 {
+			t.Errorf("Expected a single projects request, got '%d'", n)
+		}
+	})
+
+	t.Run("without a TTL", func(t *testing.T) {
+		cl := api.client(t, nil)
+		before := api.requestCount("GET /marketplace/resources/")
+
+		for i := 0; i < 2; i++ {
+			_, err := cl.GetResources(ctx, project, res)
+			expectNoError(t, err)
+		}
+
+		if n := api.requestCount("GET /marketplace/resources/") - before; n != 2 {
+			t.Errorf("Expected resources not to be cached, got '%d' requests", n)
+		}
+	})
+
+	t.Run("with multiple resources", func(t *testing.T) {
+		cl := api.client(t, nil)
+		before := api.requestCount("GET /marketplace/resources/")
+
+		creds, err := cl.GetProjectCredentialValues(ctx, &primitives.Project{
+			Name:      "website",
+			Resources: []*primitives.Resource{{Name: "db"}, {Name: "cache"}},
+		})
+		expectNoError(t, err)
+		expectStringEqual(t, creds["db"][0].Value, "pg")
+		expectStringEqual(t, creds["cache"][0].Value, "redis")
+
+		if n := api.requestCount("GET /marketplace/resources/") - before; n != 1 {
+			t.Errorf("Expected a single resources request for both resources, got '%d'", n)
+		}
+	})
+
+	t.Run("with multiple resources partly cached", func(t *testing.T) {
+		cl := api.client(t, nil)
+		cl.LookupTTL = time.Minute
+
+		_, err := cl.GetResources(ctx, project, res)
+		expectNoError(t, err)
+
+		rs, err := cl.GetResources(ctx, project, []*primitives.Resource{{Name: "db"}, {Name: "cache"}})
+		expectNoError(t, err)
+		if len(rs) != 2 || rs[0].Body.Label != "db" || rs[1].Body.Label != "cache" {
+			t.Errorf("Expected db and cache once each, got '%v'", rs)
+		}
+	})
+
+	t.Run("with an invalidated resource", func(t *testing.T) {
+		cl := api.client(t, nil)
+		cl.LookupTTL = time.Minute
+		before := api.requestCount("GET /marketplace/resources/")
+
+		_, err := cl.GetResources(ctx, project, res)
+		expectNoError(t, err)
+
+		cl.InvalidateResource("db")
+		_, err = cl.GetResources(ctx, project, res)
+		expectNoError(t, err)
+
+		if n := api.requestCount("GET /marketplace/resources/") - before; n != 2 {
+			t.Errorf("Expected two resources requests, got '%d'", n)
+		}
+	})
+
+	t.Run("with an expired TTL", func(t *testing.T) {
+		cl := api.client(t, nil)
+		cl.LookupTTL = time.Millisecond
+		before := api.requestCount("GET /marketplace/projects")
+
+		_, err := cl.ProjectIDContext(ctx, project)
+		expectNoError(t, err)
+
+		time.Sleep(5 * time.Millisecond)
+		_, err = cl.ProjectIDContext(ctx, project)
+		expectNoError(t, err)
+
+		if n := api.requestCount("GET /marketplace/projects") - before; n != 2 {
+			t.Errorf("Expected two projects requests, got '%d'", n)
+		}
+	})
+
+	t.Run("with a cancelled context", func(t *testing.T) {
+		cl := api.client(t, nil)
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if _, err := cl.ProjectIDContext(cctx, project); err == nil {
+			t.Fatal("Expected an error for a cancelled context")
+		}
+	})
+}
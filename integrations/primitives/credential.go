@@ -1,6 +1,8 @@
 package primitives
 
-import "log"
+import (
+	manifold "github.com/manifoldco/go-manifold"
+)
 
 // Credential represents the specification that is required to filter out
 // specific credentials in the Resource spec.
@@ -10,14 +12,29 @@ type Credential struct {
 	Default string `json:"default,omitempty"`
 }
 
-// Valid will validate the CredentialSpec.
+// Valid will validate the CredentialSpec. It only checks that the key is set,
+// use Validate to also check it is an uppercase credential key.
 func (c *Credential) Valid() bool {
-	if c.Key == "" {
-		log.Printf("Credential: invalid key")
-		return false
-	}
+	return c.Key != ""
+}
+
+// Validate validates the Credential and returns a ValidationError listing all
+// the invalid fields.
+func (c *Credential) Validate() error {
+	verr := &ValidationError{}
+	c.validate("", verr)
+	return verr.errOrNil()
+}
+
+func (c *Credential) validate(prefix string, verr *ValidationError) {
+	field := fieldPath(prefix, "key")
 
-	return true
+	switch {
+	case c.Key == "":
+		verr.add(field, "is required")
+	case manifold.CredentialKey(c.Key).Validate(nil) != nil:
+		verr.add(field, "must be an uppercase credential key, like DATABASE_URL")
+	}
 }
 
 // CredentialValue is a simple representation of the actual key/value of a
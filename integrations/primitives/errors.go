@@ -0,0 +1,57 @@
+package primitives
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a single invalid field in a manifest. The Field is the
+// path to the value as it appears in the manifest, for example
+// `resources[2].credentials[0].key`.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError aggregates every problem found while validating a manifest.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+
+	return fmt.Sprintf("invalid manifest:\n  %s", strings.Join(msgs, "\n  "))
+}
+
+// add records a new FieldError for the given field.
+func (e *ValidationError) add(field, msg string) {
+	e.Errors = append(e.Errors, &FieldError{Field: field, Message: msg})
+}
+
+// errOrNil returns the ValidationError if it contains any errors.
+func (e *ValidationError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// fieldPath joins a prefix and a field name into a manifest path.
+func fieldPath(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+
+	return prefix + "." + field
+}
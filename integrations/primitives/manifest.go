@@ -0,0 +1,134 @@
+package primitives
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ManifestFiles are the file names LoadManifest looks for when it is given a
+// directory, in order of preference.
+var ManifestFiles = []string{"manifold.yml", "manifold.yaml", "manifold.json"}
+
+// ErrManifestNotFound is returned when a directory does not contain any of the
+// ManifestFiles.
+var ErrManifestNotFound = errors.New("no manifold.yml or manifold.json manifest found")
+
+// LoadManifest reads and validates the Project manifest at the given path. If
+// the path is a directory, the first of the ManifestFiles that exists within
+// it is used.
+//
+// When the manifest is invalid, the returned error is a ValidationError.
+func LoadManifest(path string) (*Project, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		path, err = findManifest(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ParseManifest(data)
+	if err != nil {
+		if _, ok := err.(*ValidationError); ok {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "could not parse %s", path)
+	}
+
+	return p, nil
+}
+
+// ParseManifest decodes and validates a Project manifest. Both YAML and JSON
+// are accepted, using the same field names as the JSON representation of
+// a Project.
+func ParseManifest(data []byte) (*Project, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	// YAML decodes objects with interface keys, which can't be represented in
+	// JSON. We normalize them so we only have a single decoding path based on
+	// the JSON tags.
+	normalized, err := normalizeYAML(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+
+	p := &Project{}
+	if err := dec.Decode(p); err != nil {
+		return nil, err
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func findManifest(dir string) (string, error) {
+	for _, name := range ManifestFiles {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", ErrManifestNotFound
+}
+
+func normalizeYAML(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string key, got %v", k)
+			}
+
+			nv, err := normalizeYAML(val)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = nv
+		}
+		return m, nil
+	case []interface{}:
+		for i, val := range t {
+			nv, err := normalizeYAML(val)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = nv
+		}
+		return t, nil
+	default:
+		return v, nil
+	}
+}
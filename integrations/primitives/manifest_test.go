@@ -0,0 +1,176 @@
+package primitives
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	expect := &Project{
+		Name: "website",
+		Team: "manifold",
+		Resources: []*Resource{
+			{
+				Name: "db",
+				Credentials: []*Credential{
+					{Key: "DATABASE_URL", Name: "DB_URL"},
+					{Key: "DATABASE_POOL", Default: "5"},
+				},
+			},
+			{Name: "cache", Product: "memcachier", Plan: "dev"},
+		},
+	}
+
+	t.Run("with yaml", func(t *testing.T) {
+		p, err := ParseManifest([]byte(`
+project: website
+team: manifold
+resources:
+- resource: db
+  credentials:
+  - key: DATABASE_URL
+    name: DB_URL
+  - key: DATABASE_POOL
+    default: "5"
+- resource: cache
+  product: memcachier
+  plan: dev
+`))
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+
+		if !reflect.DeepEqual(p, expect) {
+			t.Errorf("Expected %+v, got %+v", expect, p)
+		}
+	})
+
+	t.Run("with json", func(t *testing.T) {
+		p, err := ParseManifest([]byte(`{
+			"project": "website",
+			"team": "manifold",
+			"resources": [
+				{"resource": "db", "credentials": [
+					{"key": "DATABASE_URL", "name": "DB_URL"},
+					{"key": "DATABASE_POOL", "default": "5"}
+				]},
+				{"resource": "cache", "product": "memcachier", "plan": "dev"}
+			]
+		}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+
+		if !reflect.DeepEqual(p, expect) {
+			t.Errorf("Expected %+v, got %+v", expect, p)
+		}
+	})
+
+	t.Run("with unknown fields", func(t *testing.T) {
+		_, err := ParseManifest([]byte("project: website\nresorces: []\n"))
+		if err == nil {
+			t.Fatal("Expected an error for an unknown field")
+		}
+	})
+
+	t.Run("with an invalid manifest", func(t *testing.T) {
+		_, err := ParseManifest([]byte(`
+project: My Website
+resources:
+- resource: db
+- resource: cache
+  plan: dev
+- resource: db
+  credentials:
+  - key: DATABASE_URL
+  - key: lowercase
+  - name: ALIAS
+- resource: search
+  product: elastic
+`))
+
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatalf("Expected a ValidationError, got '%v'", err)
+		}
+
+		fields := []string{}
+		for _, fe := range verr.Errors {
+			fields = append(fields, fe.Field)
+		}
+
+		expectFields := []string{
+			"project",
+			"resources[1].product",
+			"resources[2].credentials[1].key",
+			"resources[2].credentials[2].key",
+			"resources[2].resource",
+			"resources[3].plan",
+		}
+		if !reflect.DeepEqual(fields, expectFields) {
+			t.Errorf("Expected errors for %v, got %v", expectFields, fields)
+		}
+	})
+}
+
+func TestValid(t *testing.T) {
+	// Valid only checks the names are set, the label and key rules only
+	// apply to manifests.
+	p := &Project{
+		Name: "w",
+		Resources: []*Resource{
+			{Name: "d", Credentials: []*Credential{{Key: "Database_Url"}}},
+		},
+	}
+
+	if !p.Valid() {
+		t.Error("Expected the project to be valid")
+	}
+	if p.Validate() == nil {
+		t.Error("Expected the project not to pass manifest validation")
+	}
+
+	for _, p := range []*Project{
+		{},
+		{Name: "website", Resources: []*Resource{{}}},
+		{Name: "website", Resources: []*Resource{{Name: "db", Credentials: []*Credential{{}}}}},
+	} {
+		if p.Valid() {
+			t.Errorf("Expected %+v to be invalid", p)
+		}
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("without a manifest", func(t *testing.T) {
+		_, err := LoadManifest(dir)
+		if err != ErrManifestNotFound {
+			t.Fatalf("Expected '%s', got '%v'", ErrManifestNotFound, err)
+		}
+	})
+
+	t.Run("with a manifest in the directory", func(t *testing.T) {
+		path := filepath.Join(dir, "manifold.json")
+		if err := ioutil.WriteFile(path, []byte(`{"project": "website"}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := LoadManifest(dir)
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+
+		if p.Name != "website" {
+			t.Errorf("Expected project 'website', got '%s'", p.Name)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package primitives
 
 import (
 	"fmt"
+
+	manifold "github.com/manifoldco/go-manifold"
 )
 
 // Project is the specification that is required to build a valid Project
@@ -12,10 +14,10 @@ type Project struct {
 	Resources []*Resource `json:"resources,omitempty"`
 }
 
-// Valid will validate the Project.
+// Valid will validate the Project. It only checks that the names are set,
+// use Validate to also check them against the label rules of a manifest.
 func (p *Project) Valid() bool {
 	if p.Name == "" {
-		fmt.Println("no project spec name")
 		return false
 	}
 
@@ -27,3 +29,36 @@ func (p *Project) Valid() bool {
 
 	return true
 }
+
+// Validate validates the Project and all of its Resources and returns a
+// ValidationError listing all the invalid fields.
+func (p *Project) Validate() error {
+	verr := &ValidationError{}
+
+	validateLabel("project", p.Name, true, verr)
+	validateLabel("team", p.Team, false, verr)
+
+	seen := map[string]bool{}
+	for i, r := range p.Resources {
+		prefix := fmt.Sprintf("resources[%d]", i)
+		r.validate(prefix, verr)
+
+		if r.Name != "" && seen[r.Name] {
+			verr.add(fieldPath(prefix, "resource"), fmt.Sprintf("%q is listed more than once", r.Name))
+		}
+		seen[r.Name] = true
+	}
+
+	return verr.errOrNil()
+}
+
+func validateLabel(field, value string, required bool, verr *ValidationError) {
+	switch {
+	case value == "":
+		if required {
+			verr.add(field, "is required")
+		}
+	case manifold.Label(value).Validate(nil) != nil:
+		verr.add(field, fmt.Sprintf("%q is not a valid label", value))
+	}
+}
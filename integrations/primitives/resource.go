@@ -10,12 +10,17 @@ type Resource struct {
 	Name        string        `json:"resource,name"`
 	Team        string        `json:"team,omitempty"`
 	Credentials []*Credential `json:"credentials,omitempty"`
+
+	// Product and Plan are the labels used to provision the resource when it
+	// does not exist yet. A resource without a Product is a custom resource.
+	Product string `json:"product,omitempty"`
+	Plan    string `json:"plan,omitempty"`
 }
 
-// Valid will validate the Resource.
+// Valid will validate the Resource. It only checks that the names and keys
+// are set, use Validate to also check them against the rules of a manifest.
 func (r *Resource) Valid() bool {
 	if r.Name == "" {
-		fmt.Println("no resource spec label")
 		return false
 	}
 
@@ -27,3 +32,29 @@ func (r *Resource) Valid() bool {
 
 	return true
 }
+
+// Validate validates the Resource and its Credentials and returns a
+// ValidationError listing all the invalid fields.
+func (r *Resource) Validate() error {
+	verr := &ValidationError{}
+	r.validate("", verr)
+	return verr.errOrNil()
+}
+
+func (r *Resource) validate(prefix string, verr *ValidationError) {
+	validateLabel(fieldPath(prefix, "resource"), r.Name, true, verr)
+	validateLabel(fieldPath(prefix, "team"), r.Team, false, verr)
+	validateLabel(fieldPath(prefix, "product"), r.Product, false, verr)
+	validateLabel(fieldPath(prefix, "plan"), r.Plan, false, verr)
+
+	if r.Plan != "" && r.Product == "" {
+		verr.add(fieldPath(prefix, "product"), "is required when a plan is given")
+	}
+	if r.Product != "" && r.Plan == "" {
+		verr.add(fieldPath(prefix, "plan"), "is required when a product is given")
+	}
+
+	for i, c := range r.Credentials {
+		c.validate(fieldPath(prefix, fmt.Sprintf("credentials[%d]", i)), verr)
+	}
+}
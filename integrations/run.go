@@ -0,0 +1,239 @@
+package integrations
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+// Precedence decides which value wins when a credential and a variable of the
+// parent environment share the same name.
+type Precedence int
+
+const (
+	// PrecedenceManifold overrides the parent environment with the
+	// credentials. This is the default.
+	PrecedenceManifold Precedence = iota
+
+	// PrecedenceEnvironment keeps the values from the parent environment,
+	// which allows overriding credentials locally.
+	PrecedenceEnvironment
+)
+
+const defaultStopTimeout = 10 * time.Second
+
+// RunOpts holds the optional values for Run.
+type RunOpts struct {
+	// Precedence decides if credentials override the parent environment.
+	Precedence Precedence
+
+	// Environ is the parent environment the credentials are merged into. It
+	// defaults to os.Environ().
+	Environ []string
+
+	// Stdin, Stdout and Stderr of the child process. They default to the ones
+	// of the current process.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Signals are forwarded to the child process. They default to interrupt
+	// and SIGTERM.
+	Signals []os.Signal
+
+	// RestartInterval enables polling for rotated credentials. When the
+	// credentials change, the child process is stopped and started again
+	// with the new values. Polling is disabled when this is zero.
+	RestartInterval time.Duration
+
+	// StopTimeout is how long the child process is given to exit after being
+	// sent SIGTERM before it is killed. It defaults to 10 seconds.
+	StopTimeout time.Duration
+}
+
+// Run resolves the credentials of the project, and runs the given command
+// with them merged into its environment. It returns the exit code of the
+// child process once it exits, or 128 plus the signal number if it was killed
+// by a signal.
+//
+// When the context is cancelled, the child process is stopped and the
+// context's error is returned alongside the exit code.
+func (c *Client) Run(ctx context.Context, project *primitives.Project, name string, args []string, opts *RunOpts) (int, error) {
+	if opts == nil {
+		opts = &RunOpts{}
+	}
+
+	creds, err := c.projectCredentials(ctx, project)
+	if err != nil {
+		return -1, err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signals := opts.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	signal.Notify(sigs, signals...)
+	defer signal.Stop(sigs)
+
+	for {
+		cmd := exec.Command(name, args...)
+		cmd.Env = mergeEnviron(opts, creds)
+		cmd.Stdin = opts.Stdin
+		cmd.Stdout = opts.Stdout
+		cmd.Stderr = opts.Stderr
+		if cmd.Stdin == nil {
+			cmd.Stdin = os.Stdin
+		}
+		if cmd.Stdout == nil {
+			cmd.Stdout = os.Stdout
+		}
+		if cmd.Stderr == nil {
+			cmd.Stderr = os.Stderr
+		}
+
+		if err := cmd.Start(); err != nil {
+			return -1, err
+		}
+
+		rotated, code, err := c.supervise(ctx, cmd, project, creds, sigs, opts)
+		if rotated == nil {
+			return code, err
+		}
+
+		creds = rotated
+	}
+}
+
+// supervise waits for the child process to exit, forwarding signals to it.
+// If the credentials rotate, the process is stopped and the new credentials
+// are returned so it can be restarted.
+func (c *Client) supervise(ctx context.Context, cmd *exec.Cmd, project *primitives.Project,
+	creds map[string]string, sigs <-chan os.Signal, opts *RunOpts) (map[string]string, int, error) {
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	var tick <-chan time.Time
+	if opts.RestartInterval > 0 {
+		ticker := time.NewTicker(opts.RestartInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case err := <-done:
+			code, err := exitCode(cmd, err)
+			return nil, code, err
+		case sig := <-sigs:
+			cmd.Process.Signal(sig)
+		case <-ctx.Done():
+			code, _ := stopProcess(cmd, done, opts)
+			return nil, code, ctx.Err()
+		case <-tick:
+			next, err := c.projectCredentials(ctx, project)
+			if err != nil || reflect.DeepEqual(next, creds) {
+				// Keep running with the current credentials, we'll try
+				// again on the next tick.
+				continue
+			}
+
+			if _, err := stopProcess(cmd, done, opts); err != nil {
+				return nil, -1, err
+			}
+			return next, 0, nil
+		}
+	}
+}
+
+func (c *Client) projectCredentials(ctx context.Context, project *primitives.Project) (map[string]string, error) {
+	creds, err := c.GetProjectCredentialValues(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	return FlattenResourcesCredentialValues(creds)
+}
+
+// stopProcess sends SIGTERM to the process and kills it if it doesn't exit
+// within the StopTimeout.
+func stopProcess(cmd *exec.Cmd, done <-chan error, opts *RunOpts) (int, error) {
+	timeout := opts.StopTimeout
+	if timeout == 0 {
+		timeout = defaultStopTimeout
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		// Not every platform supports SIGTERM, fall back to killing it.
+		cmd.Process.Kill()
+	}
+
+	select {
+	case err := <-done:
+		return exitCode(cmd, err)
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		return exitCode(cmd, <-done)
+	}
+}
+
+// exitCode extracts the exit code of a finished process. A non zero exit is
+// not considered an error, it is reported through the code. Like shells do, a
+// process killed by a signal exits with 128 plus the signal number.
+func exitCode(cmd *exec.Cmd, err error) (int, error) {
+	if _, ok := err.(*exec.ExitError); err != nil && !ok || cmd.ProcessState == nil {
+		return -1, err
+	}
+
+	if sig, ok := killedBy(cmd.ProcessState); ok {
+		return 128 + sig, nil
+	}
+
+	return cmd.ProcessState.ExitCode(), nil
+}
+
+// mergeEnviron merges the credentials into the parent environment according
+// to the configured Precedence.
+func mergeEnviron(opts *RunOpts, creds map[string]string) []string {
+	environ := opts.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+
+	parent := map[string]bool{}
+	env := make([]string, 0, len(environ)+len(creds))
+	for _, kv := range environ {
+		k := strings.SplitN(kv, "=", 2)[0]
+		parent[k] = true
+
+		if _, ok := creds[k]; ok && opts.Precedence == PrecedenceManifold {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	keys := make([]string, 0, len(creds))
+	for k := range creds {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if parent[k] && opts.Precedence == PrecedenceEnvironment {
+			continue
+		}
+		env = append(env, k+"="+creds[k])
+	}
+
+	return env
+}
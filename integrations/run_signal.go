@@ -0,0 +1,19 @@
+//go:build !plan9
+// +build !plan9
+
+package integrations
+
+import (
+	"os"
+	"syscall"
+)
+
+// killedBy returns the number of the signal which killed the process, if any.
+func killedBy(ps *os.ProcessState) (int, bool) {
+	ws, ok := ps.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return 0, false
+	}
+
+	return int(ws.Signal()), true
+}
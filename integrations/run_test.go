@@ -0,0 +1,158 @@
+package integrations_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+// TestHelperProcess isn't a real test. It's used as the child process in the
+// Run tests, printing the TOKEN variable and exiting with EXIT_CODE, or
+// killing itself with HELPER_KILL.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	fmt.Printf("TOKEN=%s\n", os.Getenv("TOKEN"))
+
+	if os.Getenv("HELPER_SLEEP") != "" {
+		time.Sleep(time.Minute)
+	}
+
+	if os.Getenv("HELPER_KILL") != "" {
+		p, _ := os.FindProcess(os.Getpid())
+		p.Signal(os.Kill)
+		time.Sleep(time.Minute)
+	}
+
+	code, _ := strconv.Atoi(os.Getenv("EXIT_CODE"))
+	os.Exit(code)
+}
+
+func helperRunOpts(out *syncBuffer, env ...string) *integrations.RunOpts {
+	return &integrations.RunOpts{
+		Environ: append([]string{"GO_WANT_HELPER_PROCESS=1"}, env...),
+		Stdout:  out,
+		Stderr:  out,
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	helper := []string{"-test.run=TestHelperProcess"}
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	pid := api.addProject("website", nil)
+	rid := api.addResource("secrets", nil, &pid, map[string]string{"TOKEN": "from-manifold"})
+	project := &primitives.Project{Name: "website"}
+
+	t.Run("with the manifold precedence", func(t *testing.T) {
+		out := &syncBuffer{}
+		code, err := api.client(t, nil).Run(ctx, project, os.Args[0], helper,
+			helperRunOpts(out, "TOKEN=from-env", "EXIT_CODE=3"))
+		expectNoError(t, err)
+
+		if code != 3 {
+			t.Errorf("Expected exit code '3', got '%d'", code)
+		}
+		expectStringEqual(t, firstLine(out), "TOKEN=from-manifold")
+	})
+
+	t.Run("with the environment precedence", func(t *testing.T) {
+		out := &syncBuffer{}
+		opts := helperRunOpts(out, "TOKEN=from-env")
+		opts.Precedence = integrations.PrecedenceEnvironment
+
+		code, err := api.client(t, nil).Run(ctx, project, os.Args[0], helper, opts)
+		expectNoError(t, err)
+
+		if code != 0 {
+			t.Errorf("Expected exit code '0', got '%d'", code)
+		}
+		expectStringEqual(t, firstLine(out), "TOKEN=from-env")
+	})
+
+	t.Run("with rotating credentials", func(t *testing.T) {
+		out := &syncBuffer{}
+		opts := helperRunOpts(out, "HELPER_SLEEP=1")
+		opts.RestartInterval = 10 * time.Millisecond
+		opts.StopTimeout = time.Second
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go func() {
+			for !strings.Contains(out.String(), "from-manifold") {
+				time.Sleep(5 * time.Millisecond)
+			}
+			api.setValues(rid, map[string]string{"TOKEN": "rotated"})
+
+			for !strings.Contains(out.String(), "rotated") {
+				time.Sleep(5 * time.Millisecond)
+			}
+			cancel()
+		}()
+
+		_, err := api.client(t, nil).Run(ctx, project, os.Args[0], helper, opts)
+		expectErrorEqual(t, err, context.Canceled)
+		expectStringEqual(t, out.String(), "TOKEN=from-manifold\nTOKEN=rotated\n")
+	})
+
+	t.Run("with a process killed by a signal", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Processes aren't killed by signals on windows")
+		}
+
+		out := &syncBuffer{}
+		code, err := api.client(t, nil).Run(ctx, project, os.Args[0], helper,
+			helperRunOpts(out, "HELPER_KILL=1"))
+		expectNoError(t, err)
+
+		if code != 128+9 {
+			t.Errorf("Expected exit code '137', got '%d'", code)
+		}
+	})
+
+	t.Run("with an unknown command", func(t *testing.T) {
+		_, err := api.client(t, nil).Run(ctx, project, "manifold-does-not-exist", nil, nil)
+		if err == nil {
+			t.Fatal("Expected an error for an unknown command")
+		}
+	})
+}
+
+func firstLine(b *syncBuffer) string {
+	return strings.SplitN(b.String(), "\n", 2)[0]
+}
+
+// syncBuffer is a bytes.Buffer that can be written to by a child process
+// while being read by the test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
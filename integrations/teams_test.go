@@ -0,0 +1,82 @@
+package integrations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+func TestMultipleTeams(t *testing.T) {
+	ctx := context.Background()
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	web := api.addTeam("web")
+	platform := api.addTeam("platform")
+
+	pid := api.addProject("website", &web)
+	api.addResource("db", &web, &pid, map[string]string{"DATABASE_URL": "pg"})
+	api.addResource("logs", &platform, nil, map[string]string{"LOG_TOKEN": "tok"})
+
+	cl := api.client(t, strPtr("web"))
+
+	t.Run("with resources owned by another team", func(t *testing.T) {
+		creds, err := cl.GetProjectCredentialValues(ctx, &primitives.Project{
+			Name: "website",
+			Resources: []*primitives.Resource{
+				{Name: "db"},
+				{Name: "logs", Team: "platform"},
+			},
+		})
+		expectNoError(t, err)
+
+		expectStringEqual(t, creds["db"][0].Value, "pg")
+		expectStringEqual(t, creds["logs"][0].Value, "tok")
+	})
+
+	t.Run("with a project owned by another team", func(t *testing.T) {
+		c := api.client(t, nil)
+		creds, err := c.GetProjectCredentialValues(ctx, &primitives.Project{
+			Name:      "website",
+			Team:      "web",
+			Resources: []*primitives.Resource{{Name: "db"}},
+		})
+		expectNoError(t, err)
+		expectStringEqual(t, creds["db"][0].Value, "pg")
+	})
+
+	t.Run("with a resource in the wrong team", func(t *testing.T) {
+		_, err := cl.GetResources(ctx, nil, []*primitives.Resource{{Name: "logs"}})
+		expectErrorEqual(t, err, integrations.ErrResourceNotFound)
+	})
+
+	t.Run("with cached team IDs", func(t *testing.T) {
+		teams := api.requestCount("GET /identity/teams")
+
+		id, err := cl.TeamIDForLabel(ctx, "platform")
+		expectNoError(t, err)
+		expectStringEqual(t, id.String(), platform.String())
+
+		if api.requestCount("GET /identity/teams") != teams {
+			t.Error("Expected the team ID to be cached")
+		}
+	})
+
+	t.Run("with an unknown team", func(t *testing.T) {
+		_, err := cl.GetResources(ctx, nil, []*primitives.Resource{{Name: "logs", Team: "unknown"}})
+
+		terr, ok := err.(*integrations.TeamError)
+		if !ok || terr.Team != "unknown" || terr.Err != integrations.ErrTeamNotFound {
+			t.Fatalf("Expected a team not found error for 'unknown', got '%v'", err)
+		}
+		expectStringEqual(t, err.Error(), `team "unknown": team not found`)
+
+		if !errors.Is(err, integrations.ErrTeamNotFound) {
+			t.Errorf("Expected the error to wrap ErrTeamNotFound, got '%v'", err)
+		}
+	})
+}
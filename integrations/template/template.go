@@ -0,0 +1,266 @@
+// Package template renders configuration files from Go text/template files
+// with the credentials of Manifold resources.
+//
+// Templates reference credentials with the cred function, or every credential
+// of a resource with the resource function:
+//
+//	database_url: {{ cred "postgres" "DATABASE_URL" }}
+//	{{ range $key, $value := resource "redis" }}
+//	{{ $key }}={{ $value }}
+//	{{ end }}
+//
+// The referenced resources are discovered from the template, and their
+// credentials are resolved through GetResourcesCredentialValues before
+// rendering. Referencing a key that doesn't exist is an error, unless a
+// default is declared for it in the resource's primitives.Credential.
+package template
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/internal/atomicfile"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+// DefaultPerm is the file mode RenderFile uses when none is given. Rendered
+// files hold secrets, so they are only readable by their owner.
+const DefaultPerm os.FileMode = 0600
+
+// ErrInvalidReference is returned when a cred or resource call in a template
+// doesn't use string literals, which prevents discovering the resources it
+// references.
+var ErrInvalidReference = errors.New("cred and resource must be called with string literals")
+
+// KeyError is returned when a template references a credential that doesn't
+// exist and has no declared default.
+type KeyError struct {
+	Resource string
+	Key      string
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("credential %s/%s is not found", e.Resource, e.Key)
+}
+
+// Renderer renders templates with the credentials of a project's resources.
+type Renderer struct {
+	client    *integrations.Client
+	project   *string
+	resources []*primitives.Resource
+}
+
+// New returns a Renderer resolving credentials through the client. The
+// project is optional, like in GetResourcesCredentialValues. The resources
+// are optional too; they are used to declare credential defaults and aliases
+// for the resources referenced by the templates.
+func New(client *integrations.Client, project *string, resources []*primitives.Resource) *Renderer {
+	return &Renderer{
+		client:    client,
+		project:   project,
+		resources: resources,
+	}
+}
+
+// Render parses the template text and writes the rendered output to w.
+// Nothing is written if a credential can't be resolved.
+func (r *Renderer) Render(ctx context.Context, w io.Writer, name, text string) error {
+	// The functions are only placeholders while parsing, they're replaced by
+	// the ones bound to the credentials before executing.
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs(nil)).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	names, err := references(tmpl)
+	if err != nil {
+		return err
+	}
+
+	creds := map[string][]*primitives.CredentialValue{}
+	if len(names) > 0 {
+		creds, err = r.client.GetResourcesCredentialValues(ctx, r.project, r.requested(names))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rendering to a buffer first makes sure nothing is written when a
+	// credential can't be resolved halfway through.
+	out := &bytes.Buffer{}
+	if err := tmpl.Funcs(funcs(creds)).Execute(out, nil); err != nil {
+		return err
+	}
+
+	_, err = out.WriteTo(w)
+	return err
+}
+
+// RenderFile renders the template at src and writes the output to dst. The
+// output is written atomically with the given permissions, so readers never
+// see a partially rendered file. If perm is zero, DefaultPerm is used.
+func (r *Renderer) RenderFile(ctx context.Context, src, dst string, perm os.FileMode) error {
+	text, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	out := &bytes.Buffer{}
+	if err := r.Render(ctx, out, filepath.Base(src), string(text)); err != nil {
+		return err
+	}
+
+	if perm == 0 {
+		perm = DefaultPerm
+	}
+
+	return atomicfile.WriteFile(dst, out.Bytes(), perm)
+}
+
+// requested returns the resources to fetch for the referenced names, using
+// the declared resource when there is one.
+func (r *Renderer) requested(names []string) []*primitives.Resource {
+	res := make([]*primitives.Resource, len(names))
+	for i, n := range names {
+		res[i] = &primitives.Resource{Name: n}
+		for _, d := range r.resources {
+			if d.Name == n {
+				res[i] = d
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+func funcs(creds map[string][]*primitives.CredentialValue) template.FuncMap {
+	return template.FuncMap{
+		"cred": func(res, key string) (string, error) {
+			for _, cv := range creds[res] {
+				if cv.Key == key {
+					return credentialValue(cv), nil
+				}
+			}
+
+			return "", &KeyError{Resource: res, Key: key}
+		},
+		"resource": func(res string) map[string]string {
+			values := map[string]string{}
+			for _, cv := range creds[res] {
+				values[cv.Key] = credentialValue(cv)
+			}
+			return values
+		},
+	}
+}
+
+func credentialValue(cv *primitives.CredentialValue) string {
+	if cv.Value == "" {
+		return cv.Default
+	}
+
+	return cv.Value
+}
+
+// references walks the parsed templates and returns the sorted names of the
+// resources referenced by cred and resource calls.
+func references(tmpl *template.Template) ([]string, error) {
+	names := map[string]bool{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+
+		if err := walk(t.Tree.Root, names); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]string, 0, len(names))
+	for n := range names {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
+
+func walk(node parse.Node, names map[string]bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := walk(c, names); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return walk(n.Pipe, names)
+	case *parse.TemplateNode:
+		return walk(n.Pipe, names)
+	case *parse.IfNode:
+		return walkBranch(&n.BranchNode, names)
+	case *parse.RangeNode:
+		return walkBranch(&n.BranchNode, names)
+	case *parse.WithNode:
+		return walkBranch(&n.BranchNode, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			if err := walk(c, names); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		return walkCommand(n, names)
+	}
+
+	return nil
+}
+
+func walkBranch(n *parse.BranchNode, names map[string]bool) error {
+	for _, c := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if err := walk(c, names); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func walkCommand(n *parse.CommandNode, names map[string]bool) error {
+	if id, ok := n.Args[0].(*parse.IdentifierNode); ok && (id.Ident == "cred" || id.Ident == "resource") {
+		if len(n.Args) < 2 {
+			return ErrInvalidReference
+		}
+
+		s, ok := n.Args[1].(*parse.StringNode)
+		if !ok {
+			return ErrInvalidReference
+		}
+		names[s.Text] = true
+	}
+
+	for _, a := range n.Args {
+		if err := walk(a, names); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
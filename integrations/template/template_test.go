@@ -0,0 +1,164 @@
+package template_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	manifold "github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+	"github.com/manifoldco/go-manifold/integrations/template"
+)
+
+// newServer serves the resources and credentials endpoints for resources with
+// the given credential values.
+func newServer(values map[string]map[string]string) *httptest.Server {
+	resources := []manifold.Resource{}
+	credentials := []manifold.Credential{}
+	for label, v := range values {
+		r := manifold.Resource{ID: manifold.MustNewID(idtype.Resource)}
+		r.Body.Label = label
+		resources = append(resources, r)
+
+		c := manifold.Credential{ID: manifold.MustNewID(idtype.Credential)}
+		c.Body.ResourceID = r.ID
+		c.Body.Values = v
+		credentials = append(credentials, c)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/marketplace/resources/":
+			json.NewEncoder(w).Encode(resources)
+		case "/marketplace/credentials":
+			ids := r.URL.Query()["resource_id"]
+			out := []manifold.Credential{}
+			for _, c := range credentials {
+				for _, id := range ids {
+					if id == c.Body.ResourceID.String() {
+						out = append(out, c)
+					}
+				}
+			}
+			json.NewEncoder(w).Encode(out)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRender(t *testing.T) {
+	ctx := context.Background()
+
+	srv := newServer(map[string]map[string]string{
+		"postgres": {"DATABASE_URL": "postgres://db"},
+		"redis":    {"REDIS_URL": "redis://cache", "REDIS_PASSWORD": "s3cr3t"},
+	})
+	defer srv.Close()
+
+	cl, err := integrations.NewClient(manifold.New(manifold.ForURLPattern(srv.URL+"/%s")), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got '%s'", err)
+	}
+
+	render := func(r *template.Renderer, text string) (string, error) {
+		out := &bytes.Buffer{}
+		err := r.Render(ctx, out, "test", text)
+		return out.String(), err
+	}
+
+	t.Run("with existing credentials", func(t *testing.T) {
+		out, err := render(template.New(cl, nil, nil),
+			`db: {{ cred "postgres" "DATABASE_URL" }}{{ range $k, $v := resource "redis" }}
+{{ $k }}={{ $v }}{{ end }}`)
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+
+		expected := "db: postgres://db\nREDIS_PASSWORD=s3cr3t\nREDIS_URL=redis://cache"
+		if out != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, out)
+		}
+	})
+
+	t.Run("with a missing key", func(t *testing.T) {
+		out, err := render(template.New(cl, nil, nil), `before {{ cred "postgres" "MISSING" }}`)
+		if err == nil {
+			t.Fatal("Expected an error for the missing key")
+		}
+
+		if out != "" {
+			t.Errorf("Expected nothing to be written, got '%s'", out)
+		}
+	})
+
+	t.Run("with a declared default", func(t *testing.T) {
+		res := []*primitives.Resource{{
+			Name: "postgres",
+			Credentials: []*primitives.Credential{
+				{Key: "DATABASE_URL"},
+				{Key: "POOL_SIZE", Default: "5"},
+			},
+		}}
+
+		out, err := render(template.New(cl, nil, res), `{{ cred "postgres" "POOL_SIZE" }}`)
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+
+		if out != "5" {
+			t.Errorf("Expected '5', got '%s'", out)
+		}
+	})
+
+	t.Run("with a missing resource", func(t *testing.T) {
+		_, err := render(template.New(cl, nil, nil), `{{ cred "mysql" "DATABASE_URL" }}`)
+		if err != integrations.ErrResourceNotFound {
+			t.Fatalf("Expected '%s', got '%v'", integrations.ErrResourceNotFound, err)
+		}
+	})
+
+	t.Run("with a dynamic reference", func(t *testing.T) {
+		_, err := render(template.New(cl, nil, nil), `{{ $r := "postgres" }}{{ cred $r "DATABASE_URL" }}`)
+		if err != template.ErrInvalidReference {
+			t.Fatalf("Expected '%s', got '%v'", template.ErrInvalidReference, err)
+		}
+	})
+
+	t.Run("when rendering a file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "manifold-template")
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+		defer os.RemoveAll(dir)
+
+		src := filepath.Join(dir, "config.yml.tmpl")
+		dst := filepath.Join(dir, "config.yml")
+		ioutil.WriteFile(src, []byte(`url: {{ cred "postgres" "DATABASE_URL" }}`), 0644)
+
+		if err := template.New(cl, nil, nil).RenderFile(ctx, src, dst, 0); err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+
+		fi, err := os.Stat(dst)
+		if err != nil {
+			t.Fatalf("Expected no error, got '%s'", err)
+		}
+		if fi.Mode().Perm() != template.DefaultPerm {
+			t.Errorf("Expected mode '%s', got '%s'", template.DefaultPerm, fi.Mode().Perm())
+		}
+
+		b, _ := ioutil.ReadFile(dst)
+		if string(b) != "url: postgres://db" {
+			t.Errorf("Expected 'url: postgres://db', got '%s'", b)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package integrations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+// maxWatchBackoff is the longest Watch waits between polls after errors.
+const maxWatchBackoff = 5 * time.Minute
+
+// ErrWatchInterval is returned by Watch when the interval isn't positive.
+var ErrWatchInterval = errors.New("the watch interval must be positive")
+
+// CredentialEventType describes what happened to a credential.
+type CredentialEventType string
+
+const (
+	// CredentialAdded is emitted when a new key shows up for a resource
+	CredentialAdded CredentialEventType = "added"
+
+	// CredentialRemoved is emitted when a key is no longer present
+	CredentialRemoved CredentialEventType = "removed"
+
+	// CredentialChanged is emitted when the value of a key is rotated
+	CredentialChanged CredentialEventType = "changed"
+
+	// CredentialWatchError is emitted when polling failed. Watch keeps
+	// polling, backing off until the API recovers.
+	CredentialWatchError CredentialEventType = "error"
+)
+
+// CredentialEvent describes a change to a single credential of a resource.
+//
+// Values are never part of an event. Instead, OldHash and NewHash hold a keyed
+// hash of the values which can be compared to each other within the same
+// Watch, but not across different watches.
+type CredentialEvent struct {
+	Type     CredentialEventType
+	Resource string
+	Key      string
+	OldHash  string
+	NewHash  string
+	Err      error
+}
+
+// Watch polls the credentials of the given resources at the given interval
+// and emits an event for every credential that is added, removed or rotated.
+// Keys and resources are resolved the same way as
+// GetResourcesCredentialValues does.
+//
+// The current credentials are loaded before Watch returns, which means an
+// error is returned if they can't be loaded at all. Later errors are emitted
+// as CredentialWatchError events and polling backs off exponentially. The
+// channel is closed when the context is done.
+//
+// ErrWatchInterval is returned if the interval isn't positive.
+func (c *Client) Watch(ctx context.Context, project *string, res []*primitives.Resource, interval time.Duration) (<-chan *CredentialEvent, error) {
+	if interval <= 0 {
+		return nil, ErrWatchInterval
+	}
+
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	poll := func() (map[string]map[string]string, error) {
+		creds, err := c.GetResourcesCredentialValues(ctx, project, res)
+		if err != nil {
+			return nil, err
+		}
+
+		return hashCredentials(key, creds), nil
+	}
+
+	current, err := poll()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan *CredentialEvent)
+	go func() {
+		defer close(events)
+
+		wait := interval
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			next, err := poll()
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				wait *= 2
+				if wait > maxWatchBackoff {
+					wait = maxWatchBackoff
+				}
+
+				if !sendEvent(ctx, events, &CredentialEvent{Type: CredentialWatchError, Err: err}) {
+					return
+				}
+				continue
+			}
+
+			wait = interval
+			for _, evt := range diffCredentials(current, next) {
+				if !sendEvent(ctx, events, evt) {
+					return
+				}
+			}
+			current = next
+		}
+	}()
+
+	return events, nil
+}
+
+func sendEvent(ctx context.Context, events chan<- *CredentialEvent, evt *CredentialEvent) bool {
+	select {
+	case events <- evt:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// hashCredentials maps every resource to its keys and the keyed hash of
+// their values.
+func hashCredentials(key []byte, creds map[string][]*primitives.CredentialValue) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(creds))
+	for res, values := range creds {
+		hashes := make(map[string]string, len(values))
+		for _, cv := range values {
+			v := cv.Value
+			if v == "" {
+				v = cv.Default
+			}
+
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(v))
+			hashes[cv.Key] = hex.EncodeToString(mac.Sum(nil))
+		}
+		out[res] = hashes
+	}
+
+	return out
+}
+
+// diffCredentials returns the events between two sets of hashed credentials,
+// sorted by resource and key.
+func diffCredentials(prev, next map[string]map[string]string) []*CredentialEvent {
+	events := []*CredentialEvent{}
+
+	for _, res := range unionKeys(prev, next) {
+		old, cur := prev[res], next[res]
+
+		keys := map[string]bool{}
+		for k := range old {
+			keys[k] = true
+		}
+		for k := range cur {
+			keys[k] = true
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			oh, hadOld := old[k]
+			nh, hasNew := cur[k]
+
+			evt := &CredentialEvent{Resource: res, Key: k, OldHash: oh, NewHash: nh}
+			switch {
+			case !hadOld:
+				evt.Type = CredentialAdded
+			case !hasNew:
+				evt.Type = CredentialRemoved
+			case oh != nh:
+				evt.Type = CredentialChanged
+			default:
+				continue
+			}
+
+			events = append(events, evt)
+		}
+	}
+
+	return events
+}
+
+func unionKeys(a, b map[string]map[string]string) []string {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	out := make([]string, 0, len(keys))
+	for k := range keys {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+
+	return out
+}
@@ -0,0 +1,113 @@
+package integrations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold/integrations"
+	"github.com/manifoldco/go-manifold/integrations/primitives"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	api := newFakeAPI()
+	defer api.Close()
+
+	pid := api.addProject("website", nil)
+	rid := api.addResource("secrets", nil, &pid, map[string]string{
+		"TOKEN":    "abc",
+		"PASSWORD": "s3cr3t",
+	})
+
+	project := strPtr("website")
+	res := []*primitives.Resource{{Name: "secrets"}}
+
+	t.Run("with a non-existing resource", func(t *testing.T) {
+		_, err := api.client(t, nil).Watch(ctx, project, []*primitives.Resource{{Name: "missing"}}, time.Millisecond)
+		expectErrorEqual(t, err, integrations.ErrResourceNotFound)
+	})
+
+	t.Run("with a non-positive interval", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			_, err := api.client(t, nil).Watch(ctx, project, res, interval)
+			expectErrorEqual(t, err, integrations.ErrWatchInterval)
+		}
+	})
+
+	events, err := api.client(t, nil).Watch(ctx, project, res, 5*time.Millisecond)
+	expectNoError(t, err)
+
+	next := func(t *testing.T) *integrations.CredentialEvent {
+		select {
+		case evt := <-events:
+			return evt
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected an event, got none")
+			return nil
+		}
+	}
+
+	t.Run("with rotated credentials", func(t *testing.T) {
+		api.setValues(rid, map[string]string{
+			"TOKEN":   "def",
+			"API_KEY": "new",
+		})
+
+		expect := []struct {
+			typ integrations.CredentialEventType
+			key string
+		}{
+			{integrations.CredentialAdded, "API_KEY"},
+			{integrations.CredentialRemoved, "PASSWORD"},
+			{integrations.CredentialChanged, "TOKEN"},
+		}
+
+		for _, e := range expect {
+			evt := next(t)
+			if evt.Type != e.typ || evt.Key != e.key || evt.Resource != "secrets" {
+				t.Fatalf("Expected %s event for 'secrets/%s', got %+v", e.typ, e.key, evt)
+			}
+
+			if e.typ == integrations.CredentialChanged && (evt.OldHash == evt.NewHash || evt.NewHash == "") {
+				t.Errorf("Expected different hashes for a changed value, got %+v", evt)
+			}
+
+			for _, h := range []string{evt.OldHash, evt.NewHash} {
+				if h == "def" || h == "new" || h == "abc" {
+					t.Errorf("Expected hashes not to contain the values, got %+v", evt)
+				}
+			}
+		}
+	})
+
+	t.Run("with an unavailable API", func(t *testing.T) {
+		api.setFailing(true)
+
+		evt := next(t)
+		if evt.Type != integrations.CredentialWatchError || evt.Err == nil {
+			t.Fatalf("Expected an error event, got %+v", evt)
+		}
+
+		api.setFailing(false)
+		api.setValues(rid, map[string]string{"TOKEN": "def"})
+
+		evt = next(t)
+		for evt.Type == integrations.CredentialWatchError {
+			evt = next(t)
+		}
+
+		if evt.Type != integrations.CredentialRemoved || evt.Key != "API_KEY" {
+			t.Fatalf("Expected the watch to recover, got %+v", evt)
+		}
+	})
+
+	t.Run("when the context is cancelled", func(t *testing.T) {
+		cancel()
+
+		for range events {
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package names
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/manifoldco/go-manifold"
+)
+
+// DefaultMaxAttempts is the number of labels an Allocator tries before giving
+// up, unless its MaxAttempts is set.
+const DefaultMaxAttempts = 32
+
+// ErrNoFreeLabel is returned when every label tried by an Allocator exists.
+var ErrNoFreeLabel = errors.New("could not find a free label")
+
+// LabelExists reports whether a label is already in use.
+type LabelExists func(ctx context.Context, label manifold.Label) (bool, error)
+
+// Allocator finds free labels for resources, trying alternative word
+// combinations derived from their ID when a label is already in use.
+type Allocator struct {
+	Exists LabelExists
+
+	// Generator generates the labels, the default generator if nil.
+	Generator *Generator
+
+	// MaxAttempts is the number of labels tried, DefaultMaxAttempts if zero.
+	MaxAttempts int
+}
+
+// NewAllocator returns an Allocator checking labels with the given callback.
+func NewAllocator(exists LabelExists) *Allocator {
+	return &Allocator{Exists: exists}
+}
+
+// ForResource returns the first free label out of the alternatives for the
+// resource, starting with the one returned by the generator's ForResource. The
+// same inputs and existing labels always result in the same label.
+func (a *Allocator) ForResource(ctx context.Context, product manifold.Label, id manifold.ID) (manifold.Label, error) {
+	max := a.MaxAttempts
+	if max <= 0 {
+		max = DefaultMaxAttempts
+	}
+
+	g := a.Generator
+	if g == nil {
+		g = defaultGenerator
+	}
+
+	tried := map[manifold.Label]bool{}
+	for attempt := 0; attempt < max; attempt++ {
+		label := g.Alternative(product, id, attempt)
+		if tried[label] {
+			continue
+		}
+		tried[label] = true
+
+		exists, err := a.Exists(ctx, label)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return label, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w after %d attempts", ErrNoFreeLabel, max)
+}
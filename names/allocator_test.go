@@ -0,0 +1,97 @@
+package names
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func TestAllocator_ForResource(t *testing.T) {
+	product := manifold.Label("degraffdb")
+	rid, _ := manifold.DecodeIDFromString("268d37vht44f1e3t0n07jjx4d0qe8")
+
+	first := ForResource(product, rid)
+	second := Alternative(product, rid, 1)
+	third := Alternative(product, rid, 2)
+	hashed := Alternative(product, rid, 3)
+
+	errLookup := errors.New("lookup failed")
+
+	tests := []struct {
+		name    string
+		taken   []manifold.Label
+		fail    bool
+		max     int
+		want    manifold.Label
+		wantErr error
+	}{
+		{name: "free label", want: first},
+		{name: "taken label", taken: []manifold.Label{first}, want: second},
+		{name: "remaining entropy used", taken: []manifold.Label{first, second}, want: third},
+		{name: "hashed alternative", taken: []manifold.Label{first, second, third}, want: hashed},
+		{name: "no free label", taken: []manifold.Label{first, second}, max: 2, wantErr: ErrNoFreeLabel},
+		{name: "failing lookup", fail: true, wantErr: errLookup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAllocator(func(_ context.Context, label manifold.Label) (bool, error) {
+				if tt.fail {
+					return false, errLookup
+				}
+				for _, l := range tt.taken {
+					if l == label {
+						return true, nil
+					}
+				}
+				return false, nil
+			})
+			a.MaxAttempts = tt.max
+
+			got, err := a.ForResource(context.Background(), product, rid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Allocator.ForResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Allocator.ForResource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlternative(t *testing.T) {
+	product := manifold.Label("degraffdb")
+	rid, _ := manifold.DecodeIDFromString("268d37vht44f1e3t0n07jjx4d0qe8")
+
+	seen := map[manifold.Label]bool{}
+	for i := 0; i < DefaultMaxAttempts; i++ {
+		label := Alternative(product, rid, i)
+		if label != Alternative(product, rid, i) {
+			t.Fatalf("Alternative(%d) is not deterministic", i)
+		}
+		if err := label.Validate(nil); err != nil {
+			t.Fatalf("Alternative(%d) = %q is invalid: %v", i, label, err)
+		}
+		seen[label] = true
+	}
+
+	if len(seen) < DefaultMaxAttempts-1 {
+		t.Errorf("Alternative() returned %d distinct labels out of %d", len(seen), DefaultMaxAttempts)
+	}
+
+	t.Run("with sortable IDs", func(t *testing.T) {
+		id := manifold.MustNewSortableID(idtype.Resource)
+		size := defaultGenerator.combinationBytes()
+
+		// The timestamp is skipped, attempts use the random bytes in order.
+		for i := 0; i < 2; i++ {
+			want := id[sortablePayloadOffset+i*size : sortablePayloadOffset+(i+1)*size]
+			if got := defaultGenerator.alternativeBytes(id, i); !bytes.Equal(got, want) {
+				t.Errorf("alternativeBytes(%d) = %x, want %x", i, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package names
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/manifoldco/go-manifold"
+)
+
+// DefaultLocale is the locale of the word lists generated from names/data.
+const DefaultLocale = "en"
+
+const (
+	// payloadOffset skips the version and type bytes of an ID.
+	payloadOffset = 2
+
+	// Sortable IDs, see manifold.NewSortableID, start their payload with a
+	// millisecond timestamp which is skipped too, as it barely changes
+	// between IDs.
+	sortableIDVersion     = 0x2
+	sortablePayloadOffset = payloadOffset + 6
+)
+
+var (
+	// ErrEmptyWordList is returned when a Generator is given an empty word
+	// list, or one emptied by its denylist.
+	ErrEmptyWordList = errors.New("word list is empty")
+	// ErrWordListsTooBig is returned when the word lists need more bits than
+	// an ID holds.
+	ErrWordListsTooBig = errors.New("word lists are too big")
+	// ErrUnknownLocale is returned when no word lists are registered for a
+	// locale.
+	ErrUnknownLocale = errors.New("unknown locale")
+)
+
+// WordLists are the lists names are picked from, in order.
+type WordLists struct {
+	Adjectives []string
+	Colors     []string
+	Shapes     []string
+}
+
+// LoadWordLists reads the adjectives.txt, colors.txt and shapes.txt files of
+// the directory, in the format used by names/data.
+func LoadWordLists(dir string) (WordLists, error) {
+	var lists WordLists
+
+	files := []struct {
+		name string
+		list *[]string
+	}{
+		{"adjectives.txt", &lists.Adjectives},
+		{"colors.txt", &lists.Colors},
+		{"shapes.txt", &lists.Shapes},
+	}
+
+	for _, f := range files {
+		fd, err := os.Open(filepath.Join(dir, f.name))
+		if err != nil {
+			return lists, err
+		}
+
+		*f.list, err = ReadWordList(fd)
+		fd.Close()
+		if err != nil {
+			return lists, err
+		}
+	}
+
+	return lists, nil
+}
+
+// ReadWordList reads a word list with one entry per line. Blank lines are
+// ignored.
+func ReadWordList(r io.Reader) ([]string, error) {
+	var words []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if w := strings.TrimSpace(scanner.Text()); w != "" {
+			words = append(words, w)
+		}
+	}
+
+	return words, scanner.Err()
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithDenylist removes the entries containing any of the given words from the
+// word lists. Words are matched case-insensitively.
+func WithDenylist(words ...string) Option {
+	return func(g *Generator) {
+		for _, w := range words {
+			g.denylist[strings.ToLower(w)] = true
+		}
+	}
+}
+
+// Generator generates names and labels from IDs, picking a word from each of
+// its lists. Each list is given the bits it needs out of the ID, and the same
+// ID always results in the same name.
+type Generator struct {
+	lists    WordLists
+	denylist map[string]bool
+
+	aShare, cShare, sShare int
+
+	indexOnce sync.Once
+	index     wordIndex
+}
+
+// NewGenerator returns a Generator using the given word lists.
+func NewGenerator(lists WordLists, opts ...Option) (*Generator, error) {
+	g := &Generator{denylist: map[string]bool{}}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	g.lists = WordLists{
+		Adjectives: g.filter(lists.Adjectives),
+		Colors:     g.filter(lists.Colors),
+		Shapes:     g.filter(lists.Shapes),
+	}
+
+	if len(g.lists.Adjectives) == 0 || len(g.lists.Colors) == 0 || len(g.lists.Shapes) == 0 {
+		return nil, ErrEmptyWordList
+	}
+
+	g.aShare = bitsNeeded(len(g.lists.Adjectives))
+	g.cShare = bitsNeeded(len(g.lists.Colors))
+	g.sShare = bitsNeeded(len(g.lists.Shapes))
+
+	if g.combinationBytes()*8 > entropy {
+		return nil, ErrWordListsTooBig
+	}
+
+	return g, nil
+}
+
+// MustNewGenerator returns a Generator like NewGenerator, and panics if the
+// word lists are invalid.
+func MustNewGenerator(lists WordLists, opts ...Option) *Generator {
+	g, err := NewGenerator(lists, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return g
+}
+
+// New returns a generated name based on the provided id, and its matching label.
+// Names are title cased with spaces between words.
+// Labels are lowercased with hyphens between words
+func (g *Generator) New(id manifold.ID) (string, string) {
+	adj, color, shape := g.words(g.alternativeBytes(id, 0))
+
+	name := strings.Title(adj + " " + color + " " + shape)
+	label := strings.Replace(strings.ToLower(name), " ", "-", -1)
+	return name, label
+}
+
+// ForResource returns a new label combining a product label with a random label
+// for the resource based on its id.
+func (g *Generator) ForResource(product manifold.Label, id manifold.ID) manifold.Label {
+	return g.Alternative(product, id, 0)
+}
+
+// Alternative returns the resource label for the given attempt. The first
+// attempt returns the same label as ForResource, the following ones use the
+// remaining bytes of the ID and then hashes of it.
+func (g *Generator) Alternative(product manifold.Label, id manifold.ID, attempt int) manifold.Label {
+	adj, color, shape := g.words(g.alternativeBytes(id, attempt))
+
+	label := fmt.Sprintf("%s-%s-%s-%s", product, adj, color, shape)
+	label = strings.ToLower(strings.Replace(label, " ", "-", -1))
+
+	return manifold.Label(label)
+}
+
+// alternativeBytes returns the bytes the words of an attempt are picked from.
+func (g *Generator) alternativeBytes(id manifold.ID, attempt int) []byte {
+	p := payload(id)
+	size := g.combinationBytes()
+
+	if offset := attempt * size; offset+size <= len(p) {
+		return append([]byte{}, p[offset:offset+size]...)
+	}
+
+	buf := make([]byte, len(id)+8)
+	copy(buf, id[:])
+	binary.BigEndian.PutUint64(buf[len(id):], uint64(attempt))
+	sum := sha256.Sum256(buf)
+
+	return sum[:size]
+}
+
+// payload returns the random bytes of the ID.
+func payload(id manifold.ID) []byte {
+	if id[0]>>4 == sortableIDVersion {
+		return id[sortablePayloadOffset:]
+	}
+
+	return id[payloadOffset:]
+}
+
+func (g *Generator) words(b []byte) (string, string, string) {
+	offset := 0
+	adj, offset := fetchWord(b, g.lists.Adjectives, offset, g.aShare)
+	color, offset := fetchWord(b, g.lists.Colors, offset, g.cShare)
+	shape, _ := fetchWord(b, g.lists.Shapes, offset, g.sShare)
+
+	return adj, color, shape
+}
+
+// combinationBytes returns the number of bytes used to pick the words of a
+// label.
+func (g *Generator) combinationBytes() int {
+	return (g.aShare+7)/8 + (g.cShare+7)/8 + (g.sShare+7)/8
+}
+
+// filter removes the entries matching the denylist from the word list.
+func (g *Generator) filter(words []string) []string {
+	if len(g.denylist) == 0 {
+		return words
+	}
+
+	out := make([]string, 0, len(words))
+	for _, entry := range words {
+		if !g.denied(entry) {
+			out = append(out, entry)
+		}
+	}
+
+	return out
+}
+
+func (g *Generator) denied(entry string) bool {
+	entry = strings.ToLower(entry)
+	if g.denylist[entry] {
+		return true
+	}
+
+	for _, w := range strings.Fields(entry) {
+		if g.denylist[w] {
+			return true
+		}
+	}
+
+	return false
+}
+
+var locales = struct {
+	sync.RWMutex
+	lists map[string]WordLists
+}{lists: map[string]WordLists{}}
+
+// RegisterLocale registers the word lists of a locale, replacing any lists
+// previously registered for it. The DefaultLocale is registered with the
+// lists from names/data.
+func RegisterLocale(locale string, lists WordLists) {
+	locales.Lock()
+	defer locales.Unlock()
+
+	locales.lists[locale] = lists
+}
+
+// NewLocaleGenerator returns a Generator using the word lists registered for
+// the locale.
+func NewLocaleGenerator(locale string, opts ...Option) (*Generator, error) {
+	locales.RLock()
+	lists, ok := locales.lists[locale]
+	locales.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLocale, locale)
+	}
+
+	return NewGenerator(lists, opts...)
+}
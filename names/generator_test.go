@@ -0,0 +1,99 @@
+package names
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/names/data"
+)
+
+func TestNewGenerator(t *testing.T) {
+	small := WordLists{
+		Adjectives: []string{"happy", "sad"},
+		Colors:     []string{"red", "hot pink"},
+		Shapes:     []string{"circle"},
+	}
+
+	tests := []struct {
+		name    string
+		lists   WordLists
+		opts    []Option
+		wantErr error
+	}{
+		{name: "default lists", lists: WordLists{data.Adjectives, data.Colors, data.Shapes}},
+		{name: "small lists", lists: small},
+		{name: "empty list", lists: WordLists{Adjectives: small.Adjectives, Colors: small.Colors}, wantErr: ErrEmptyWordList},
+		{name: "list emptied by denylist", lists: small, opts: []Option{WithDenylist("Circle")}, wantErr: ErrEmptyWordList},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGenerator(tt.lists, tt.opts...); err != tt.wantErr {
+				t.Errorf("NewGenerator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerator_Denylist(t *testing.T) {
+	lists := WordLists{
+		Adjectives: []string{"happy", "sad"},
+		Colors:     []string{"red", "hot pink"},
+		Shapes:     []string{"circle"},
+	}
+
+	g := MustNewGenerator(lists, WithDenylist("SAD", "pink"))
+
+	for i := 0; i < 20; i++ {
+		id := manifold.MustNewID(0)
+		if _, label := g.New(id); label != "happy-red-circle" {
+			t.Fatalf("Generator.New() label = %q, want happy-red-circle", label)
+		}
+	}
+}
+
+func TestGenerator_DefaultOutput(t *testing.T) {
+	lists, err := LoadWordLists("data")
+	if err != nil {
+		t.Fatalf("LoadWordLists() error = %v", err)
+	}
+
+	g := MustNewGenerator(lists)
+	rid, _ := manifold.DecodeIDFromString("268d37vht44f1e3t0n07jjx4d0qe8")
+
+	if got, want := g.ForResource("degraffdb", rid), ForResource("degraffdb", rid); got != want {
+		t.Errorf("Generator.ForResource() = %q, want %q", got, want)
+	}
+
+	gotName, gotLabel := g.New(rid)
+	wantName, wantLabel := New(rid)
+	if gotName != wantName || gotLabel != wantLabel {
+		t.Errorf("Generator.New() = %q, %q, want %q, %q", gotName, gotLabel, wantName, wantLabel)
+	}
+}
+
+func TestNewLocaleGenerator(t *testing.T) {
+	RegisterLocale("test", WordLists{
+		Adjectives: []string{"grand"},
+		Colors:     []string{"rouge"},
+		Shapes:     []string{"cercle"},
+	})
+
+	g, err := NewLocaleGenerator("test")
+	if err != nil {
+		t.Fatalf("NewLocaleGenerator() error = %v", err)
+	}
+	if _, label := g.New(manifold.MustNewID(0)); label != "grand-rouge-cercle" {
+		t.Errorf("Generator.New() label = %q, want grand-rouge-cercle", label)
+	}
+
+	if _, err := NewLocaleGenerator(DefaultLocale); err != nil {
+		t.Errorf("NewLocaleGenerator() error = %v", err)
+	}
+
+	_, err = NewLocaleGenerator("xx")
+	if !errors.Is(err, ErrUnknownLocale) || !strings.Contains(err.Error(), `"xx"`) {
+		t.Errorf("NewLocaleGenerator() error = %v, want %v", err, ErrUnknownLocale)
+	}
+}
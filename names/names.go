@@ -2,8 +2,6 @@ package names
 
 import (
 	"encoding/binary"
-	"fmt"
-	"strings"
 
 	"github.com/manifoldco/go-manifold"
 	"github.com/manifoldco/go-manifold/names/data"
@@ -13,16 +11,14 @@ const (
 	entropy = 16 * 8 // in bits
 )
 
-var aShare, cShare, sShare int
+var defaultGenerator = MustNewGenerator(WordLists{
+	Adjectives: data.Adjectives,
+	Colors:     data.Colors,
+	Shapes:     data.Shapes,
+})
 
 func init() {
-	aShare = bitsNeeded(len(data.Adjectives))
-	cShare = bitsNeeded(len(data.Colors))
-	sShare = bitsNeeded(len(data.Shapes))
-
-	if aShare+cShare+sShare > entropy {
-		panic("Word lists are now too big")
-	}
+	RegisterLocale(DefaultLocale, defaultGenerator.lists)
 }
 
 // New returns a generated name based on the provided id, and its matching label.
@@ -30,32 +26,20 @@ func init() {
 // Labels are lowercased with hyphens between words
 // Deprecated: for resource names use `ForResource` instead
 func New(id manifold.ID) (string, string) {
-	idBytes := id[2:]
-
-	offset := 0
-	adj, offset := fetchWord(idBytes, data.Adjectives, offset, aShare)
-	color, offset := fetchWord(idBytes, data.Colors, offset, cShare)
-	shape, _ := fetchWord(idBytes, data.Shapes, offset, sShare)
-
-	name := strings.Title(adj + " " + color + " " + shape)
-	label := strings.Replace(strings.ToLower(name), " ", "-", -1)
-	return name, label
+	return defaultGenerator.New(id)
 }
 
 // ForResource returns a new label combining a product label with a random label
 // for the resource based on its id.
 func ForResource(product manifold.Label, id manifold.ID) manifold.Label {
-	idBytes := id[2:]
-
-	offset := 0
-	adj, offset := fetchWord(idBytes, data.Adjectives, offset, aShare)
-	color, offset := fetchWord(idBytes, data.Colors, offset, cShare)
-	shape, _ := fetchWord(idBytes, data.Shapes, offset, sShare)
-
-	label := fmt.Sprintf("%s-%s-%s-%s", product, adj, color, shape)
-	label = strings.ToLower(strings.Replace(label, " ", "-", -1))
+	return defaultGenerator.ForResource(product, id)
+}
 
-	return manifold.Label(label)
+// Alternative returns the resource label for the given attempt. The first
+// attempt returns the same label as ForResource, the following ones use the
+// remaining bytes of the ID and then hashes of it.
+func Alternative(product manifold.Label, id manifold.ID, attempt int) manifold.Label {
+	return defaultGenerator.Alternative(product, id, attempt)
 }
 
 func fetchWord(idBytes []byte, wordList []string, offset, bitShare int) (string, int) {
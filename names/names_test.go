@@ -52,3 +52,16 @@ func TestForResource(t *testing.T) {
 		t.Errorf("Expected %q == %q", expect, got)
 	}
 }
+
+func TestForResource_SortableIDs(t *testing.T) {
+	// Sortable IDs created within the same millisecond only differ by their
+	// random bytes.
+	seen := map[manifold.Label]bool{}
+	for i := 0; i < 20; i++ {
+		seen[ForResource("degraffdb", manifold.MustNewSortableID(idtype.Resource))] = true
+	}
+
+	if len(seen) != 20 {
+		t.Errorf("ForResource() returned %d distinct labels out of 20 sortable IDs", len(seen))
+	}
+}
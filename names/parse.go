@@ -0,0 +1,209 @@
+package names
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/manifoldco/go-manifold"
+)
+
+// ErrNotGeneratedLabel is returned when parsing a label which wasn't generated
+// from the word lists.
+var ErrNotGeneratedLabel = errors.New("label is not a generated name")
+
+// ParsedLabel is an interpretation of a generated label.
+type ParsedLabel struct {
+	Product manifold.Label
+
+	// Indices of the words in the word lists.
+	Adjective int
+	Color     int
+	Shape     int
+
+	// Prefixes lists the ID patterns the words could have been picked from,
+	// for both regular and sortable IDs. The ID of the resource matches one
+	// of them, unless its words were picked from a hash because the word
+	// lists need more bytes than its payload holds.
+	Prefixes []IDPrefix
+}
+
+// IDPrefix is a pattern matching the bytes of IDs which words are picked from.
+// Only the bits set in Mask are significant.
+type IDPrefix struct {
+	Value manifold.ID
+	Mask  manifold.ID
+}
+
+// Matches returns whether the ID matches the prefix.
+func (p IDPrefix) Matches(id manifold.ID) bool {
+	for i := range id {
+		if id[i]&p.Mask[i] != p.Value[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parse parses a label generated by ForResource, see Generator.Parse.
+func Parse(label manifold.Label) (*ParsedLabel, error) {
+	return defaultGenerator.Parse(label)
+}
+
+// ParseAll returns every interpretation of a label generated by ForResource,
+// see Generator.ParseAll.
+func ParseAll(label manifold.Label) ([]*ParsedLabel, error) {
+	return defaultGenerator.ParseAll(label)
+}
+
+// Parse returns the product and word indices of a label generated by
+// ForResource, along with the ID prefixes those words encode.
+// ErrNotGeneratedLabel is returned if the label isn't a generated one.
+//
+// As products and words can contain hyphens, a label can have multiple
+// interpretations. Parse returns the one with the longest product, use
+// ParseAll to get all of them.
+func (g *Generator) Parse(label manifold.Label) (*ParsedLabel, error) {
+	parsed, err := g.ParseAll(label)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed[0], nil
+}
+
+// ParseAll returns every interpretation of a label generated by ForResource,
+// starting with the one with the longest product.
+func (g *Generator) ParseAll(label manifold.Label) ([]*ParsedLabel, error) {
+	g.indexOnce.Do(g.buildIndex)
+
+	tokens := strings.Split(string(label), "-")
+	for _, t := range tokens {
+		if t == "" {
+			return nil, ErrNotGeneratedLabel
+		}
+	}
+
+	var parsed []*ParsedLabel
+
+	// Words are matched from the end of the label, the shortest first.
+	for shapeAt := len(tokens) - 1; shapeAt >= 3; shapeAt-- {
+		shapes := g.index.shapes[strings.Join(tokens[shapeAt:], "-")]
+		if len(shapes) == 0 {
+			continue
+		}
+
+		for colorAt := shapeAt - 1; colorAt >= 2; colorAt-- {
+			colors := g.index.colors[strings.Join(tokens[colorAt:shapeAt], "-")]
+			if len(colors) == 0 {
+				continue
+			}
+
+			for adjAt := colorAt - 1; adjAt >= 1; adjAt-- {
+				adjs := g.index.adjectives[strings.Join(tokens[adjAt:colorAt], "-")]
+				product := manifold.Label(strings.Join(tokens[:adjAt], "-"))
+
+				for _, a := range adjs {
+					for _, c := range colors {
+						for _, s := range shapes {
+							parsed = append(parsed, &ParsedLabel{
+								Product:   product,
+								Adjective: a,
+								Color:     c,
+								Shape:     s,
+								Prefixes:  g.prefixes(a, c, s),
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if len(parsed) == 0 {
+		return nil, ErrNotGeneratedLabel
+	}
+
+	return parsed, nil
+}
+
+// prefixes returns the ID patterns resulting in the words at the given
+// indices, following the bit layout of fetchWord. Sortable IDs have their own
+// patterns, as their words are picked after the timestamp.
+func (g *Generator) prefixes(a, c, s int) []IDPrefix {
+	var sortable IDPrefix
+	sortable.Value[0] = sortableIDVersion << 4
+	sortable.Mask[0] = 0xF0
+
+	return append(g.prefixesAt(IDPrefix{}, payloadOffset, a, c, s),
+		g.prefixesAt(sortable, sortablePayloadOffset, a, c, s)...)
+}
+
+// prefixesAt returns the patterns of the words picked from the bytes at the
+// offset, on top of the base pattern.
+func (g *Generator) prefixesAt(base IDPrefix, offset, a, c, s int) []IDPrefix {
+	words := []struct {
+		idx, count, share int
+	}{
+		{a, len(g.lists.Adjectives), g.aShare},
+		{c, len(g.lists.Colors), g.cShare},
+		{s, len(g.lists.Shapes), g.sShare},
+	}
+
+	// Words which don't fit in the ID are picked from a hash instead.
+	if offset+g.combinationBytes() > len(base.Value) {
+		return nil
+	}
+
+	prefixes := []IDPrefix{base}
+	for _, w := range words {
+		size := (w.share + 7) / 8
+
+		// fetchWord wraps values past the end of the list around once.
+		values := []uint64{uint64(w.idx)}
+		if v := uint64(w.idx + w.count); v < 1<<uint(w.share) {
+			values = append(values, v)
+		}
+
+		var next []IDPrefix
+		for _, p := range prefixes {
+			for _, v := range values {
+				for i := 0; i < size; i++ {
+					shift := uint(8 * (size - 1 - i))
+					p.Value[offset+i] = byte(v >> shift)
+					p.Mask[offset+i] = byte(uint64(1<<uint(w.share)-1) >> shift)
+				}
+				next = append(next, p)
+			}
+		}
+
+		prefixes = next
+		offset += size
+	}
+
+	return prefixes
+}
+
+type wordIndex struct {
+	adjectives, colors, shapes map[string][]int
+}
+
+func (g *Generator) buildIndex() {
+	g.index = wordIndex{
+		adjectives: indexWords(g.lists.Adjectives),
+		colors:     indexWords(g.lists.Colors),
+		shapes:     indexWords(g.lists.Shapes),
+	}
+}
+
+// indexWords maps words, as they appear in labels, to their indices in the
+// list. Lists can contain duplicates.
+func indexWords(words []string) map[string][]int {
+	index := make(map[string][]int, len(words))
+	for i, w := range words {
+		key := strings.ToLower(strings.Replace(w, " ", "-", -1))
+		index[key] = append(index[key], i)
+	}
+
+	return index
+}
@@ -0,0 +1,111 @@
+package names
+
+import (
+	"testing"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+	"github.com/manifoldco/go-manifold/names/data"
+)
+
+func TestParse(t *testing.T) {
+	rid, _ := manifold.DecodeIDFromString("268d37vht44f1e3t0n07jjx4d0qe8")
+
+	tests := []struct {
+		name    string
+		label   manifold.Label
+		product manifold.Label
+		words   []string
+		wantErr error
+	}{
+		{
+			name:    "generated label",
+			label:   "degraffdb-each-heliotrope-octagon",
+			product: "degraffdb",
+			words:   []string{"each", "heliotrope", "octagon"},
+		},
+		{
+			name:    "hyphenated product",
+			label:   "jawsdb-mysql-each-heliotrope-octagon",
+			product: "jawsdb-mysql",
+			words:   []string{"each", "heliotrope", "octagon"},
+		},
+		{name: "unknown words", label: "degraffdb-not-a-name", wantErr: ErrNotGeneratedLabel},
+		{name: "missing product", label: "each-heliotrope-octagon", wantErr: ErrNotGeneratedLabel},
+		{name: "empty word", label: "degraffdb--heliotrope-octagon", wantErr: ErrNotGeneratedLabel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.label)
+			if err != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Product != tt.product {
+				t.Errorf("Parse() product = %q, want %q", got.Product, tt.product)
+			}
+			words := []string{data.Adjectives[got.Adjective], data.Colors[got.Color], data.Shapes[got.Shape]}
+			for i := range words {
+				if words[i] != tt.words[i] {
+					t.Errorf("Parse() words = %v, want %v", words, tt.words)
+				}
+			}
+
+			if !matchesAny(got.Prefixes, rid) {
+				t.Errorf("Parse() prefixes %v don't match %v", got.Prefixes, rid)
+			}
+		})
+	}
+}
+
+func TestParseAll_RoundTrip(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		id := manifold.MustNewID(idtype.Resource)
+		if i%2 == 1 {
+			id = manifold.MustNewSortableID(idtype.Resource)
+		}
+		label := ForResource("jawsdb-mysql", id)
+
+		parsed, err := ParseAll(label)
+		if err != nil {
+			t.Fatalf("ParseAll(%q) error = %v", label, err)
+		}
+
+		found := false
+		for _, p := range parsed {
+			if p.Product == "jawsdb-mysql" && matchesAny(p.Prefixes, id) {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("ParseAll(%q) has no interpretation matching %v", label, id)
+		}
+	}
+}
+
+func TestIDPrefix_Matches(t *testing.T) {
+	id := manifold.MustNewID(idtype.Resource)
+	other := id
+	other[3] ^= 0xff
+
+	parsed, err := Parse(ForResource("db", id))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if matchesAny(parsed.Prefixes, other) {
+		t.Errorf("IDPrefix.Matches() matched an ID with different word bits")
+	}
+}
+
+func matchesAny(prefixes []IDPrefix, id manifold.ID) bool {
+	for _, p := range prefixes {
+		if p.Matches(id) {
+			return true
+		}
+	}
+	return false
+}
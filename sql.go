@@ -0,0 +1,115 @@
+package manifold
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value implements the database/sql/driver.Valuer interface. IDs are stored
+// in their base32 text form.
+func (id ID) Value() (driver.Value, error) {
+	return id.String(), nil
+}
+
+// Scan implements the database/sql.Scanner interface. It accepts both the
+// base32 text form and the raw 18 bytes of an ID.
+func (id *ID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return id.fillID([]byte(v))
+	case []byte:
+		if len(v) == byteLength {
+			copy(id[:], v)
+			return nil
+		}
+		return id.fillID(v)
+	default:
+		return fmt.Errorf("cannot scan %T into an ID, use NullID for nullable columns", src)
+	}
+}
+
+// Value implements the database/sql/driver.Valuer interface. FlexIDs are
+// stored in their `domain/class/id` text form.
+func (id FlexID) Value() (driver.Value, error) {
+	return id.String(), nil
+}
+
+// Scan implements the database/sql.Scanner interface. It accepts the
+// `domain/class/id` text form, or the text form of a Manifold ID.
+func (id *FlexID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case []byte:
+		return id.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a FlexID, use NullFlexID for nullable columns", src)
+	}
+}
+
+// NullID represents an ID that may be null. It can be used as a scan
+// destination, similar to sql.NullString.
+type NullID struct {
+	ID    ID
+	Valid bool // Valid is true if ID is not NULL
+}
+
+// Value implements the database/sql/driver.Valuer interface.
+func (n NullID) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return n.ID.Value()
+}
+
+// Scan implements the database/sql.Scanner interface.
+func (n *NullID) Scan(src interface{}) error {
+	if src == nil {
+		n.ID, n.Valid = ID{}, false
+		return nil
+	}
+
+	n.Valid = true
+	return n.ID.Scan(src)
+}
+
+// NullFlexID represents a FlexID that may be null. It can be used as a scan
+// destination, similar to sql.NullString.
+type NullFlexID struct {
+	FlexID FlexID
+	Valid  bool // Valid is true if FlexID is not NULL
+}
+
+// Value implements the database/sql/driver.Valuer interface.
+func (n NullFlexID) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return n.FlexID.Value()
+}
+
+// Scan implements the database/sql.Scanner interface.
+func (n *NullFlexID) Scan(src interface{}) error {
+	if src == nil {
+		n.FlexID, n.Valid = FlexID{}, false
+		return nil
+	}
+
+	n.Valid = true
+	return n.FlexID.Scan(src)
+}
+
+// Ensure interface adherence
+var (
+	_ driver.Valuer = ID{}
+	_ sql.Scanner   = &ID{}
+	_ driver.Valuer = FlexID{}
+	_ sql.Scanner   = &FlexID{}
+	_ driver.Valuer = NullID{}
+	_ sql.Scanner   = &NullID{}
+	_ driver.Valuer = NullFlexID{}
+	_ sql.Scanner   = &NullFlexID{}
+)
@@ -0,0 +1,240 @@
+package manifold
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+// fakeDriver is a database/sql driver storing a single column table in memory.
+// `INSERT` appends its argument to the table, `SELECT` returns every row and
+// `RAW` returns the values converted to bytes, like a binary column would.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows []driver.Value
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	s.d.rows = append(s.d.rows, args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	rows := make([]driver.Value, len(s.d.rows))
+	copy(rows, s.d.rows)
+	s.d.rows = nil
+
+	if s.query == "RAW" {
+		for i, r := range rows {
+			if s, ok := r.(string); ok {
+				rows[i] = []byte(s)
+			}
+		}
+	}
+
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	dest[0], r.rows = r.rows[0], r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("manifold-fake", &fakeDriver{})
+}
+
+func roundTrip(t *testing.T, query string, in interface{}, out interface{}) error {
+	db, err := sql.Open("manifold-fake", "")
+	if err != nil {
+		t.Fatalf("Could not open the fake database: %s", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT", in); err != nil {
+		t.Fatalf("Could not insert %v: %s", in, err)
+	}
+
+	return db.QueryRow(query).Scan(out)
+}
+
+func TestID_SQL(t *testing.T) {
+	id := MustNewID(idtype.User)
+
+	t.Run("text form", func(t *testing.T) {
+		var out ID
+		if err := roundTrip(t, "SELECT", id, &out); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if out != id {
+			t.Errorf("Scan() = %s, want %s", out, id)
+		}
+	})
+
+	t.Run("binary column", func(t *testing.T) {
+		var out ID
+		if err := roundTrip(t, "RAW", id, &out); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if out != id {
+			t.Errorf("Scan() = %s, want %s", out, id)
+		}
+	})
+
+	t.Run("raw bytes", func(t *testing.T) {
+		var out ID
+		if err := roundTrip(t, "SELECT", id[:], &out); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if out != id {
+			t.Errorf("Scan() = %s, want %s", out, id)
+		}
+	})
+
+	t.Run("null", func(t *testing.T) {
+		var out ID
+		if err := roundTrip(t, "SELECT", nil, &out); err == nil {
+			t.Error("Scan() expected an error for NULL")
+		}
+	})
+}
+
+func TestID_Scan(t *testing.T) {
+	id := MustNewID(idtype.User)
+
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    ID
+		wantErr bool
+	}{
+		{name: "string", src: id.String(), want: id},
+		{name: "text bytes", src: []byte(id.String()), want: id},
+		{name: "raw bytes", src: id[:], want: id},
+		{name: "invalid string", src: "abc", wantErr: true},
+		{name: "invalid bytes", src: []byte{1, 2, 3}, wantErr: true},
+		{name: "unsupported type", src: int64(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ID
+			err := got.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ID.Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ID.Scan() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlexID_SQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      interface{}
+		want    FlexID
+		wantErr bool
+	}{
+		{name: "FlexID", in: *validFlexID, want: *validFlexID},
+		{name: "external FlexID", in: FlexID{"web.com", "user", "abc123"}, want: FlexID{"web.com", "user", "abc123"}},
+		{name: "Manifold ID text", in: validID.String(), want: *validFlexID},
+		{name: "invalid text", in: "web.com/user", wantErr: true},
+		{name: "null", in: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got FlexID
+			err := roundTrip(t, "RAW", tt.in, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FlexID.Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("FlexID.Scan() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNullID_SQL(t *testing.T) {
+	id := MustNewID(idtype.User)
+
+	tests := []struct {
+		name string
+		in   NullID
+	}{
+		{name: "valid", in: NullID{ID: id, Valid: true}},
+		{name: "null", in: NullID{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NullID{ID: MustNewID(idtype.User), Valid: true}
+			if err := roundTrip(t, "SELECT", tt.in, &got); err != nil {
+				t.Fatalf("NullID.Scan() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.in) {
+				t.Errorf("NullID.Scan() = %v, want %v", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestNullFlexID_SQL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   NullFlexID
+	}{
+		{name: "valid", in: NullFlexID{FlexID: *validFlexID, Valid: true}},
+		{name: "null", in: NullFlexID{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NullFlexID{FlexID: FlexID{"web.com", "user", "abc123"}, Valid: true}
+			if err := roundTrip(t, "SELECT", tt.in, &got); err != nil {
+				t.Fatalf("NullFlexID.Scan() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.in) {
+				t.Errorf("NullFlexID.Scan() = %v, want %v", got, tt.in)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"text/template"
+)
+
+type typedID struct {
+	Name string
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		log.Fatal("usage: typed-ids OUTFILE TYPE...")
+	}
+
+	outfile := os.Args[1]
+
+	var ids []typedID
+	for _, name := range os.Args[2:] {
+		ids = append(ids, typedID{Name: name})
+	}
+
+	tmpl := template.Must(template.ParseFiles("tools/typed-ids/typed.tmpl"))
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, ids); err != nil {
+		log.Fatal(err)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(outfile, formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,27 @@
+package manifold
+
+import (
+	"github.com/manifoldco/go-manifold/errors"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+// Typed IDs are generated for the types most commonly passed around. Add a
+// type to the list below and run `go generate` to get a new one.
+//
+// Each typed ID registers a strfmt format, like `base32ResourceID`, which specs
+// can use along with an `x-go-type` of `ResourceID`.
+//
+//go:generate go run ./tools/typed-ids zz_generated_typed_ids.go Resource Project Team
+
+// ErrIDTypeMismatch is returned when a typed ID is given an ID of another
+// type.
+var ErrIDTypeMismatch = NewError(errors.BadRequestError,
+	"Invalid ID, the ID type does not match the expected type")
+
+func checkIDType(id ID, t idtype.Type) error {
+	if id.Type() != t {
+		return ErrIDTypeMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package manifold
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func TestResourceID_UnmarshalText(t *testing.T) {
+	resource := MustNewID(idtype.Resource)
+	project := MustNewID(idtype.Project)
+
+	tests := []struct {
+		name    string
+		in      string
+		wantErr error
+	}{
+		{name: "resource ID", in: resource.String()},
+		{name: "project ID", in: project.String(), wantErr: ErrIDTypeMismatch},
+		{name: "invalid ID", in: "abc", wantErr: errIDLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				ID ResourceID `json:"id"`
+			}
+			err := json.Unmarshal([]byte(`{"id":"`+tt.in+`"}`), &got)
+			if err != tt.wantErr {
+				t.Fatalf("ResourceID.UnmarshalText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.ID.AsID() != resource {
+				t.Errorf("ResourceID.UnmarshalText() = %v, want %v", got.ID, resource)
+			}
+		})
+	}
+}
+
+func TestResourceIDFromID(t *testing.T) {
+	resource := MustNewID(idtype.Resource)
+
+	id, err := ResourceIDFromID(resource)
+	if err != nil || id.AsID() != resource {
+		t.Errorf("ResourceIDFromID() = %v, %v, want %v", id, err, resource)
+	}
+
+	if _, err := ResourceIDFromID(MustNewID(idtype.Team)); err != ErrIDTypeMismatch {
+		t.Errorf("ResourceIDFromID() error = %v, want %v", err, ErrIDTypeMismatch)
+	}
+}
+
+func TestResourceID_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      ResourceID
+		wantErr error
+	}{
+		{name: "resource ID", id: ResourceID(MustNewID(idtype.Resource))},
+		{name: "empty ID", id: ResourceID{}},
+		{name: "converted team ID", id: ResourceID(MustNewID(idtype.Team)), wantErr: ErrIDTypeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.id.Validate(nil); err != tt.wantErr {
+				t.Errorf("ResourceID.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTypedID_Format(t *testing.T) {
+	team := MustNewID(idtype.Team).String()
+	project := MustNewID(idtype.Project).String()
+
+	if !strfmt.Default.Validates("base32TeamID", team) {
+		t.Errorf("base32TeamID format rejected %s", team)
+	}
+	if strfmt.Default.Validates("base32TeamID", project) {
+		t.Errorf("base32TeamID format accepted %s", project)
+	}
+
+	got, err := strfmt.Default.Parse("base32ProjectID", project)
+	if err != nil {
+		t.Fatalf("strfmt.Parse() error = %v", err)
+	}
+	if id := got.(*ProjectID); id.String() != project {
+		t.Errorf("strfmt.Parse() = %v, want %v", id, project)
+	}
+}
+
+func TestTeamID_Scan(t *testing.T) {
+	team := MustNewID(idtype.Team)
+
+	var id TeamID
+	if err := id.Scan(team.String()); err != nil || id.AsID() != team {
+		t.Errorf("TeamID.Scan() = %v, %v, want %v", id, err, team)
+	}
+
+	if err := id.Scan(MustNewID(idtype.User).String()); err != ErrIDTypeMismatch {
+		t.Errorf("TeamID.Scan() error = %v, want %v", err, ErrIDTypeMismatch)
+	}
+}
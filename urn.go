@@ -0,0 +1,118 @@
+package manifold
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/manifoldco/go-manifold/errors"
+)
+
+const (
+	urnPrefix = "urn:"
+	urlPrefix = "https://"
+)
+
+var (
+	errInvalidURN = NewError(errors.BadRequestError,
+		"Invalid Identifier, expected `urn:DOMAIN:CLASS:ID` or `https://DOMAIN/CLASS/ID`")
+
+	// idEscaper escapes the characters allowed by idRegex which aren't
+	//  allowed in URNs and URL paths. `-`, `_` and `=` are kept as is.
+	idEscaper   = strings.NewReplacer("{", "%7B", "}", "%7D")
+	idUnescaper = strings.NewReplacer("%7B", "{", "%7D", "}")
+)
+
+// URN returns the Identifier as a URN, like `urn:manifold.co:user:ID`. Curly
+// braces in the ID are percent-encoded.
+func (id FlexID) URN() string {
+	return urnPrefix + id.Domain().String() + ":" + id.Class().String() + ":" +
+		idEscaper.Replace(id.ID().String())
+}
+
+// URL returns the Identifier as a URL, like `https://manifold.co/user/ID`.
+// Curly braces in the ID are percent-encoded.
+func (id FlexID) URL() string {
+	return urlPrefix + id.Domain().String() + pathSeperator + id.Class().String() +
+		pathSeperator + idEscaper.Replace(id.ID().String())
+}
+
+// URN returns the ID as a URN, like `urn:manifold.co:user:ID`.
+func (id ID) URN() string {
+	return id.AsFlexID().URN()
+}
+
+// URL returns the ID as a URL, like `https://manifold.co/user/ID`.
+func (id ID) URL() string {
+	return id.AsFlexID().URL()
+}
+
+// ParseIdentifierStrict parses an Identifier in the exact form returned by
+// URN or URL. The scheme must be lowercase, and curly braces must be
+// percent-encoded with uppercase hex digits.
+func ParseIdentifierStrict(s string) (*FlexID, error) {
+	var parts []string
+	switch {
+	case strings.HasPrefix(s, urnPrefix):
+		parts = strings.Split(s[len(urnPrefix):], ":")
+	case strings.HasPrefix(s, urlPrefix):
+		parts = strings.Split(s[len(urlPrefix):], pathSeperator)
+	default:
+		return nil, errInvalidURN
+	}
+
+	if len(parts) != 3 || strings.ContainsAny(parts[2], "{}") {
+		return nil, errInvalidURN
+	}
+
+	id := FlexID{parts[0], parts[1], idUnescaper.Replace(parts[2])}
+	if strings.Contains(id[2], "%") {
+		return nil, errInvalidURN
+	}
+
+	if err := id.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// ParseIdentifier parses an Identifier in any of its representations: a URN,
+// a URL, the `domain/class/id` text form or a bare Manifold ID. It is lenient
+// with URNs and URLs; schemes are case-insensitive, `http` is accepted, a
+// trailing slash is ignored and the ID can be escaped or not.
+func ParseIdentifier(s string) (*FlexID, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+
+	var parts []string
+	switch {
+	case strings.HasPrefix(lower, urnPrefix):
+		parts = strings.Split(s[len(urnPrefix):], ":")
+	case strings.HasPrefix(lower, urlPrefix):
+		parts = strings.Split(strings.TrimSuffix(s[len(urlPrefix):], pathSeperator), pathSeperator)
+	case strings.HasPrefix(lower, "http://"):
+		parts = strings.Split(strings.TrimSuffix(s[len("http://"):], pathSeperator), pathSeperator)
+	default:
+		id := &FlexID{}
+		if err := id.UnmarshalText([]byte(s)); err != nil {
+			return nil, err
+		}
+		return id, nil
+	}
+
+	if len(parts) != 3 {
+		return nil, errInvalidURN
+	}
+
+	eid, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return nil, errInvalidURN
+	}
+
+	id := FlexID{strings.ToLower(parts[0]), parts[1], eid}
+	if err := id.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
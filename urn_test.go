@@ -0,0 +1,111 @@
+package manifold
+
+import (
+	"testing"
+)
+
+func TestFlexID_URN(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      Identifier
+		wantURN string
+		wantURL string
+	}{
+		{
+			name:    "plain ID",
+			id:      FlexID{"web.com", "user", "abc-123_x"},
+			wantURN: "urn:web.com:user:abc-123_x",
+			wantURL: "https://web.com/user/abc-123_x",
+		},
+		{
+			name:    "escaped ID",
+			id:      FlexID{"web.com", "user", "{abc==}"},
+			wantURN: "urn:web.com:user:%7Babc==%7D",
+			wantURL: "https://web.com/user/%7Babc==%7D",
+		},
+		{
+			name:    "manifold ID",
+			id:      validID,
+			wantURN: "urn:manifold.co:provider:" + validID.String(),
+			wantURL: "https://manifold.co/provider/" + validID.String(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.id.URN(); got != tt.wantURN {
+				t.Errorf("Identifier.URN() = %v, want %v", got, tt.wantURN)
+			}
+			if got := tt.id.URL(); got != tt.wantURL {
+				t.Errorf("Identifier.URL() = %v, want %v", got, tt.wantURL)
+			}
+
+			for _, s := range []string{tt.wantURN, tt.wantURL} {
+				got, err := ParseIdentifierStrict(s)
+				if err != nil {
+					t.Fatalf("ParseIdentifierStrict() error = %v", err)
+				}
+				if *got != *tt.id.AsFlexID() {
+					t.Errorf("ParseIdentifierStrict() = %v, want %v", got, tt.id)
+				}
+			}
+		})
+	}
+}
+
+func TestParseIdentifierStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{name: "urn", in: "urn:web.com:user:abc"},
+		{name: "url", in: "https://web.com/user/abc"},
+		{name: "uppercase scheme", in: "URN:web.com:user:abc", wantErr: true},
+		{name: "http", in: "http://web.com/user/abc", wantErr: true},
+		{name: "unescaped braces", in: "urn:web.com:user:{abc}", wantErr: true},
+		{name: "lowercase escapes", in: "urn:web.com:user:%7babc%7d", wantErr: true},
+		{name: "trailing slash", in: "https://web.com/user/abc/", wantErr: true},
+		{name: "missing part", in: "urn:web.com:abc", wantErr: true},
+		{name: "slash form", in: "web.com/user/abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseIdentifierStrict(tt.in); (err != nil) != tt.wantErr {
+				t.Errorf("ParseIdentifierStrict() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseIdentifier(t *testing.T) {
+	abc := FlexID{"web.com", "user", "abc"}
+	braces := FlexID{"web.com", "user", "{abc}"}
+
+	tests := []struct {
+		name    string
+		in      string
+		want    FlexID
+		wantErr bool
+	}{
+		{name: "urn", in: "urn:web.com:user:abc", want: abc},
+		{name: "uppercase scheme", in: " URN:Web.com:user:abc ", want: abc},
+		{name: "http", in: "http://web.com/user/abc/", want: abc},
+		{name: "unescaped braces", in: "https://web.com/user/{abc}", want: braces},
+		{name: "lowercase escapes", in: "urn:web.com:user:%7babc%7d", want: braces},
+		{name: "slash form", in: "web.com/user/abc", want: abc},
+		{name: "manifold ID", in: validID.String(), want: *validFlexID},
+		{name: "missing part", in: "urn:web.com:abc", wantErr: true},
+		{name: "invalid escape", in: "urn:web.com:user:%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIdentifier(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIdentifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != tt.want {
+				t.Errorf("ParseIdentifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}